@@ -0,0 +1,312 @@
+// Package a11y checks elem trees for common accessibility mistakes before
+// they are rendered: images without alternative text, unlabeled form
+// controls, buttons without an accessible name, invalid ARIA roles, ARIA
+// attributes referencing missing ids, skipped heading levels and duplicate
+// ids.
+//
+// Check returns the issues found so tests can assert on them, as does
+// a11ytest.AssertAccessible, and Hook returns an elem.RenderHook that runs the checks
+// on every render during development.
+package a11y
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+)
+
+// Rules reported in Issue.Rule.
+const (
+	RuleImageAlt     = "image-alt"
+	RuleLabel        = "label"
+	RuleButtonName   = "button-name"
+	RuleRole         = "aria-role"
+	RuleIDRef        = "idref"
+	RuleHeadingOrder = "heading-order"
+	RuleDuplicateID  = "duplicate-id"
+)
+
+// Issue is a single accessibility problem found by Check.
+type Issue struct {
+	// Rule identifies the check that failed, one of the Rule constants.
+	Rule string
+	// Path locates the element in the tree, in the format of elem.Change.Path.
+	Path    string
+	Message string
+	Element *elem.Element
+}
+
+// String formats the issue as "path: message (rule)".
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Path, i.Message, i.Rule)
+}
+
+// idRefAttrs are the attributes whose values are space-separated lists of
+// ids that must exist in the document.
+var idRefAttrs = []string{
+	attrs.AriaLabelledby,
+	attrs.AriaDescribedby,
+	attrs.AriaControls,
+	attrs.AriaOwns,
+	attrs.AriaFlowto,
+	attrs.AriaActivedescendant,
+	"aria-details",
+	"aria-errormessage",
+	attrs.For,
+}
+
+// Check walks the tree rooted at node and returns the accessibility issues
+// found, in document order, or nil if there are none. The checks only see the
+// tree they are given, so id references to elements outside of it are
+// reported.
+func Check(node elem.Node) []Issue {
+	c := newChecker(node)
+	elem.WalkPaths(node, func(n elem.Node, path string) bool {
+		if e, ok := n.(*elem.Element); ok && e != nil {
+			c.checkElement(e, path)
+		}
+		return true
+	})
+	return c.issues
+}
+
+// Hook returns a render hook that runs Check on the tree being rendered and
+// passes any issues to report, which must not be nil. Checking walks the
+// whole tree on every render, so the hook is meant for development:
+//
+//	opts := elem.RenderOptions{}
+//	if devMode {
+//		opts.Hook = a11y.Hook(func(issues []a11y.Issue) {
+//			for _, issue := range issues {
+//				logger.Warn("accessibility issue", "issue", issue)
+//			}
+//		})
+//	}
+//	html := page.RenderWithOptions(opts)
+func Hook(report func([]Issue)) elem.RenderHook {
+	if report == nil {
+		panic("a11y: Hook requires a report function")
+	}
+	return elem.RenderHookFunc(func(root elem.Node) {
+		if issues := Check(root); len(issues) > 0 {
+			report(issues)
+		}
+	})
+}
+
+// Collector collects the issues found on every render by its Hook, for
+// example to list them on a development page. A Collector is safe for
+// concurrent use; the zero value is ready to use.
+type Collector struct {
+	mu     sync.Mutex
+	issues []Issue
+}
+
+// Hook returns a render hook that adds the issues of each rendered tree to
+// the collector.
+func (c *Collector) Hook() elem.RenderHook {
+	return Hook(func(issues []Issue) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.issues = append(c.issues, issues...)
+	})
+}
+
+// Issues returns the issues collected so far, in the order they were found.
+func (c *Collector) Issues() []Issue {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.issues)
+}
+
+// Reset discards the collected issues.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.issues = nil
+}
+
+type checker struct {
+	ids      map[string]*elem.Element
+	labelFor map[string]bool
+	labeled  map[*elem.Element]bool
+	seenIDs  map[string]bool
+	heading  int
+	issues   []Issue
+}
+
+// newChecker collects the ids in the tree and the controls that are labeled
+// by a label element, either through its for attribute or by nesting.
+func newChecker(root elem.Node) *checker {
+	c := &checker{
+		ids:      map[string]*elem.Element{},
+		labelFor: map[string]bool{},
+		labeled:  map[*elem.Element]bool{},
+		seenIDs:  map[string]bool{},
+	}
+	elem.Walk(root, func(n elem.Node) bool {
+		e, ok := n.(*elem.Element)
+		if !ok || e == nil {
+			return true
+		}
+		if id := e.Attrs[attrs.ID]; id != "" {
+			if _, exists := c.ids[id]; !exists {
+				c.ids[id] = e
+			}
+		}
+		if e.Tag == "label" {
+			if target := e.Attrs[attrs.For]; target != "" {
+				c.labelFor[target] = true
+			}
+			elem.Walk(e, func(n elem.Node) bool {
+				if control, ok := n.(*elem.Element); ok && isLabelable(control) {
+					c.labeled[control] = true
+				}
+				return true
+			})
+		}
+		return true
+	})
+	return c
+}
+
+func (c *checker) report(rule, path string, e *elem.Element, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Rule: rule, Path: path, Message: fmt.Sprintf(format, args...), Element: e})
+}
+
+func (c *checker) checkElement(e *elem.Element, path string) {
+	if id, ok := e.Attrs[attrs.ID]; ok {
+		if c.seenIDs[id] {
+			c.report(RuleDuplicateID, path, e, "id %q is already used by another element", id)
+		}
+		c.seenIDs[id] = true
+	}
+
+	if role, ok := e.Attrs[attrs.Role]; ok {
+		c.checkRole(e, path, role)
+	}
+
+	for _, attr := range idRefAttrs {
+		value, ok := e.Attrs[attr]
+		if !ok || (attr == attrs.For && e.Tag != "label" && e.Tag != "output") {
+			continue
+		}
+		for _, id := range strings.Fields(value) {
+			if c.ids[id] == nil {
+				c.report(RuleIDRef, path, e, "%s references missing id %q", attr, id)
+			}
+		}
+	}
+
+	switch e.Tag {
+	case "img", "area":
+		if _, ok := e.Attrs[attrs.Alt]; !ok && !hasAriaName(e) && !isPresentational(e) {
+			c.report(RuleImageAlt, path, e, "<%s> has no alt attribute; use alt=\"\" for decorative images", e.Tag)
+		}
+	case "input":
+		c.checkInput(e, path)
+	case "select", "textarea":
+		c.checkLabel(e, path)
+	case "button":
+		if accessibleText(e) == "" && !hasAriaName(e) {
+			c.report(RuleButtonName, path, e, "<button> has no text content, aria-label, aria-labelledby or title")
+		}
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(e.Tag[1] - '0')
+		if c.heading > 0 && level > c.heading+1 {
+			c.report(RuleHeadingOrder, path, e, "<%s> follows <h%d>, skipping a heading level", e.Tag, c.heading)
+		}
+		c.heading = level
+	}
+}
+
+func (c *checker) checkInput(e *elem.Element, path string) {
+	switch strings.ToLower(e.Attrs[attrs.Type]) {
+	case "hidden", "submit", "reset":
+		// Hidden inputs aren't presented and the others have default labels.
+	case "button":
+		if strings.TrimSpace(e.Attrs[attrs.Value]) == "" && !hasAriaName(e) {
+			c.report(RuleButtonName, path, e, "<input type=\"button\"> has no value, aria-label, aria-labelledby or title")
+		}
+	case "image":
+		if _, ok := e.Attrs[attrs.Alt]; !ok && !hasAriaName(e) {
+			c.report(RuleImageAlt, path, e, "<input type=\"image\"> has no alt attribute")
+		}
+	default:
+		c.checkLabel(e, path)
+	}
+}
+
+func (c *checker) checkLabel(e *elem.Element, path string) {
+	id := e.Attrs[attrs.ID]
+	if c.labeled[e] || (id != "" && c.labelFor[id]) || hasAriaName(e) {
+		return
+	}
+	c.report(RuleLabel, path, e, "<%s> has no associated <label>, aria-label, aria-labelledby or title", e.Tag)
+}
+
+func (c *checker) checkRole(e *elem.Element, path, role string) {
+	tokens := strings.Fields(role)
+	if len(tokens) == 0 {
+		c.report(RuleRole, path, e, "role attribute is empty")
+		return
+	}
+	for _, token := range tokens {
+		if !roles[token] {
+			c.report(RuleRole, path, e, "%q is not a valid ARIA role", token)
+		}
+	}
+}
+
+// isLabelable reports whether e is a form control that needs a label.
+func isLabelable(e *elem.Element) bool {
+	switch e.Tag {
+	case "input", "select", "textarea", "meter", "output", "progress":
+		return true
+	}
+	return false
+}
+
+// hasAriaName reports whether e is named by an attribute rather than its
+// content.
+func hasAriaName(e *elem.Element) bool {
+	for _, attr := range []string{attrs.AriaLabel, attrs.AriaLabelledby, attrs.Title} {
+		if strings.TrimSpace(e.Attrs[attr]) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func isPresentational(e *elem.Element) bool {
+	role := e.Attrs[attrs.Role]
+	return role == "presentation" || role == "none"
+}
+
+// accessibleText approximates the name an element gets from its content:
+// its text and the alternative text of images inside it, skipping subtrees
+// hidden with aria-hidden. Raw HTML is assumed to contribute a name.
+func accessibleText(node elem.Node) string {
+	var b strings.Builder
+	elem.Walk(node, func(n elem.Node) bool {
+		switch n := n.(type) {
+		case elem.TextNode:
+			b.WriteString(string(n))
+		case elem.RawNode:
+			b.WriteString(string(n))
+		case *elem.Element:
+			if n == nil || n.Attrs[attrs.AriaHidden] == "true" {
+				return false
+			}
+			if n.Tag == "img" {
+				b.WriteString(n.Attrs[attrs.Alt])
+			}
+		}
+		return true
+	})
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,105 @@
+package a11y
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func issueStrings(issues []Issue) []string {
+	var out []string
+	for _, issue := range issues {
+		out = append(out, issue.String())
+	}
+	return out
+}
+
+func TestCheckAccessibleTree(t *testing.T) {
+	page := elem.Main(nil,
+		elem.H1(nil, elem.Text("Sign up")),
+		elem.Img(attrs.Props{attrs.Src: "logo.png", attrs.Alt: ""}),
+		elem.Form(attrs.Props{attrs.AriaDescribedby: "intro"},
+			elem.P(attrs.Props{attrs.ID: "intro"}, elem.Text("All fields are required.")),
+			elem.Label(attrs.Props{attrs.For: "email"}, elem.Text("Email")),
+			elem.Input(attrs.Props{attrs.ID: "email", attrs.Type: "email"}),
+			elem.Label(nil, elem.Text("Name"), elem.Input(attrs.Props{attrs.Type: "text"})),
+			elem.Textarea(attrs.Props{attrs.AriaLabel: "Bio"}, ""),
+			elem.Input(attrs.Props{attrs.Type: "hidden", attrs.Name: "csrf"}),
+			elem.Input(attrs.Props{attrs.Type: "submit"}),
+			elem.Button(attrs.Props{attrs.Type: "button"}, elem.Img(attrs.Props{attrs.Src: "x.svg", attrs.Alt: "Close"})),
+		),
+		elem.H2(nil, elem.Text("Details")),
+		elem.H3(nil, elem.Text("More")),
+		elem.H2(nil, elem.Text("Other")),
+		elem.Nav(attrs.Props{attrs.Role: "navigation doc-toc"}),
+	)
+	assert.Empty(t, Check(page))
+}
+
+func TestCheckReportsIssues(t *testing.T) {
+	page := elem.Div(nil,
+		elem.H2(nil, elem.Text("Title")),
+		elem.Img(attrs.Props{attrs.Src: "a.png"}),
+		elem.Input(attrs.Props{attrs.ID: "q", attrs.Type: "search"}),
+		elem.Select(nil, elem.Option(nil, elem.Text("One"))),
+		elem.Button(nil, elem.Span(attrs.Props{attrs.AriaHidden: "true"}, elem.Text("×"))),
+		elem.Input(attrs.Props{attrs.Type: "button"}),
+		elem.Div(attrs.Props{attrs.Role: "buton"}),
+		elem.Button(attrs.Props{attrs.AriaControls: "menu", attrs.ID: "q"}, elem.Text("Menu")),
+		elem.Label(attrs.Props{attrs.For: "nope"}, elem.Text("Orphan")),
+		elem.H4(nil, elem.Text("Deep")),
+	)
+
+	assert.Equal(t, []string{
+		`div/img[1]: <img> has no alt attribute; use alt="" for decorative images (image-alt)`,
+		`div/input[2]: <input> has no associated <label>, aria-label, aria-labelledby or title (label)`,
+		`div/select[3]: <select> has no associated <label>, aria-label, aria-labelledby or title (label)`,
+		`div/button[4]: <button> has no text content, aria-label, aria-labelledby or title (button-name)`,
+		`div/input[5]: <input type="button"> has no value, aria-label, aria-labelledby or title (button-name)`,
+		`div/div[6]: "buton" is not a valid ARIA role (aria-role)`,
+		`div/button[7]: id "q" is already used by another element (duplicate-id)`,
+		`div/button[7]: aria-controls references missing id "menu" (idref)`,
+		`div/label[8]: for references missing id "nope" (idref)`,
+		`div/h4[9]: <h4> follows <h2>, skipping a heading level (heading-order)`,
+	}, issueStrings(Check(page)))
+}
+
+func TestHook(t *testing.T) {
+	page := elem.Div(nil, elem.Img(attrs.Props{attrs.Src: "a.png"}))
+
+	var reported []Issue
+	html := page.RenderWithOptions(elem.RenderOptions{
+		Hook: Hook(func(issues []Issue) { reported = issues }),
+	})
+
+	assert.Equal(t, `<div><img src="a.png"></div>`, html)
+	assert.Len(t, reported, 1)
+	assert.Equal(t, RuleImageAlt, reported[0].Rule)
+	assert.Same(t, page.Children[0], reported[0].Element)
+
+	reported = nil
+	elem.Div(nil).RenderWithOptions(elem.RenderOptions{Hook: Hook(func(issues []Issue) { reported = issues })})
+	assert.Nil(t, reported, "report should not be called for accessible trees")
+}
+
+func TestHookRequiresReport(t *testing.T) {
+	assert.Panics(t, func() { Hook(nil) })
+}
+
+func TestCollector(t *testing.T) {
+	var c Collector
+	opts := elem.RenderOptions{Hook: c.Hook()}
+	elem.Img(attrs.Props{attrs.Src: "a.png"}).RenderWithOptions(opts)
+	elem.Div(nil).RenderWithOptions(opts)
+	elem.Button(nil).RenderWithOptions(opts)
+
+	assert.Equal(t, []string{
+		"img: <img> has no alt attribute; use alt=\"\" for decorative images (image-alt)",
+		"button: <button> has no text content, aria-label, aria-labelledby or title (button-name)",
+	}, issueStrings(c.Issues()))
+
+	c.Reset()
+	assert.Empty(t, c.Issues())
+}
@@ -0,0 +1,28 @@
+// Package a11ytest provides test assertions for the accessibility checks of
+// the a11y package, kept apart so that a11y doesn't depend on testing.
+package a11ytest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/a11y"
+)
+
+// AssertAccessible checks that a11y.Check finds no issues in the tree rooted
+// at node, listing every issue in the failure message otherwise.
+func AssertAccessible(t testing.TB, node elem.Node) bool {
+	t.Helper()
+	issues := a11y.Check(node)
+	if len(issues) == 0 {
+		return true
+	}
+	var b strings.Builder
+	for _, issue := range issues {
+		b.WriteString("\n  ")
+		b.WriteString(issue.String())
+	}
+	t.Errorf("found %d accessibility issues:%s", len(issues), b.String())
+	return false
+}
@@ -0,0 +1,31 @@
+package a11ytest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a testing.TB that records errors instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertAccessible(t *testing.T) {
+	assert.True(t, AssertAccessible(t, elem.Img(attrs.Props{attrs.Src: "a.png", attrs.Alt: "A"})))
+
+	r := &recorder{TB: t}
+	assert.False(t, AssertAccessible(r, elem.Div(nil, elem.Img(attrs.Props{attrs.Src: "a.png"}))))
+	assert.Equal(t, []string{"found 1 accessibility issues:\n" +
+		`  div/img[0]: <img> has no alt attribute; use alt="" for decorative images (image-alt)`}, r.errors)
+}
@@ -0,0 +1,205 @@
+package elem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// AsyncNode is a node whose content is produced by a function that may be
+// slow, e.g. because it queries a database or calls another service.
+// RenderAsync runs the functions of all async nodes in a tree concurrently
+// and then renders the tree in document order, so the output is the same as
+// if they had run one after another.
+//
+// Rendering an async node in any other way, e.g. with Render, runs its
+// function when it is reached, with a background context.
+type AsyncNode struct {
+	// Content produces the node to render.
+	Content func(ctx context.Context) (Node, error)
+	// Fallback is rendered instead if Content fails, panics, or doesn't run
+	// because the context is done. Nothing is rendered if it is nil.
+	Fallback Node
+}
+
+// Async creates an async node whose content is produced by content.
+func Async(content func(ctx context.Context) (Node, error)) *AsyncNode {
+	return &AsyncNode{Content: content}
+}
+
+// AsyncOptions configure RenderAsync.
+type AsyncOptions struct {
+	RenderOptions
+	// Workers limits how many Content functions run at once. It defaults to
+	// runtime.GOMAXPROCS(0); functions that mostly wait on I/O can use more.
+	Workers int
+}
+
+// RenderAsync renders root after running the Content functions of the async
+// nodes in it concurrently, including async nodes in the content they
+// produce. If some fail, the output has their fallbacks in place and the
+// returned error joins their errors in document order. If ctx is done before
+// all have run, the remaining ones are skipped and ctx.Err() is among the
+// errors.
+func RenderAsync(ctx context.Context, root Node, opts AsyncOptions) (string, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	results := &asyncResults{
+		ctx:     ctx,
+		sem:     make(chan struct{}, workers),
+		results: make(map[*AsyncNode]*asyncResult),
+		seen:    make(map[*AsyncNode]struct{}),
+	}
+	results.schedule(root)
+	results.wg.Wait()
+
+	renderOpts := opts.RenderOptions
+	renderOpts.async = results
+	html := root.RenderWithOptions(renderOpts)
+	return html, errors.Join(results.errs...)
+}
+
+// asyncResults runs the content functions of async nodes and holds their
+// results while the tree is rendered.
+type asyncResults struct {
+	ctx context.Context
+	sem chan struct{}
+	wg  sync.WaitGroup
+
+	mu      sync.Mutex
+	results map[*AsyncNode]*asyncResult
+
+	// seen, errs and ctxErr are used while rendering, which is sequential.
+	seen   map[*AsyncNode]struct{}
+	errs   []error
+	ctxErr bool
+}
+
+type asyncResult struct {
+	node Node
+	err  error
+}
+
+// schedule starts running the content of the async nodes in the tree of
+// node that haven't been started yet. Each waits for a worker.
+func (r *asyncResults) schedule(node Node) {
+	Walk(node, func(n Node) bool {
+		a, ok := n.(*AsyncNode)
+		if !ok || a == nil {
+			return true
+		}
+		r.mu.Lock()
+		_, started := r.results[a]
+		if !started {
+			r.results[a] = &asyncResult{}
+		}
+		r.mu.Unlock()
+		if started {
+			return false
+		}
+
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			select {
+			case r.sem <- struct{}{}:
+			case <-r.ctx.Done():
+				r.finish(a, nil, r.ctx.Err())
+				return
+			}
+			node, err := runAsync(r.ctx, a)
+			<-r.sem
+			r.finish(a, node, err)
+			if err == nil {
+				r.schedule(node)
+			}
+		}()
+		return false
+	})
+}
+
+func (r *asyncResults) finish(a *AsyncNode, node Node, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.results[a] = asyncResult{node, err}
+}
+
+// runAsync runs the content function of a, turning a panic into an error,
+// since a panic in a worker would otherwise take down the program.
+func runAsync(ctx context.Context, a *AsyncNode) (node Node, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			node, err = nil, fmt.Errorf("elem: async content panicked: %v", p)
+		}
+	}()
+	return a.Content(ctx)
+}
+
+// content returns the node to render for a, given its result.
+func (a *AsyncNode) content(node Node, err error) Node {
+	if err != nil || node == nil {
+		if a.Fallback == nil {
+			return NoneNode{}
+		}
+		return a.Fallback
+	}
+	return node
+}
+
+func (a *AsyncNode) RenderTo(builder *strings.Builder, opts RenderOptions) {
+	if opts.stream != nil {
+		opts.stream.placeholder(builder, opts, a)
+		return
+	}
+	r := opts.async
+	if r == nil {
+		node, err := runAsync(context.Background(), a)
+		a.content(node, err).RenderTo(builder, opts)
+		return
+	}
+
+	r.mu.Lock()
+	res := r.results[a]
+	r.mu.Unlock()
+	if res == nil {
+		// Not in the tree RenderAsync was given, e.g. added by a hook.
+		node, err := runAsync(r.ctx, a)
+		res = &asyncResult{node, err}
+	}
+	r.addError(a, res.err)
+	a.content(res.node, res.err).RenderTo(builder, opts)
+}
+
+// addError records the error of a, once per node and once for the nodes
+// skipped because the context is done.
+func (r *asyncResults) addError(a *AsyncNode, err error) {
+	if _, dup := r.seen[a]; dup || err == nil {
+		return
+	}
+	r.seen[a] = struct{}{}
+	if err == r.ctx.Err() {
+		if r.ctxErr {
+			return
+		}
+		r.ctxErr = true
+	}
+	r.errs = append(r.errs, err)
+}
+
+func (a *AsyncNode) Render() string {
+	return a.RenderWithOptions(RenderOptions{})
+}
+
+func (a *AsyncNode) RenderWithOptions(opts RenderOptions) string {
+	var builder strings.Builder
+	a.RenderTo(&builder, opts)
+	return builder.String()
+}
@@ -0,0 +1,106 @@
+package elem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderAsync(t *testing.T) {
+	widget := func(i int) *AsyncNode {
+		return Async(func(ctx context.Context) (Node, error) {
+			// Later widgets finish first.
+			time.Sleep(time.Duration(5-i) * time.Millisecond)
+			return Section(nil, Text(fmt.Sprint(i))), nil
+		})
+	}
+	page := Main(nil, widget(0), widget(1), Div(nil, widget(2), widget(3)), widget(4))
+
+	html, err := RenderAsync(context.Background(), page, AsyncOptions{Workers: 5})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<main><section>0</section><section>1</section><div><section>2</section><section>3</section></div><section>4</section></main>", html)
+	assert.Equal(t, html, page.Render(), "Rendering synchronously should produce the same output")
+}
+
+func TestRenderAsyncLimitsWorkers(t *testing.T) {
+	var running, peak int32
+	var children []Node
+	for i := 0; i < 12; i++ {
+		children = append(children, Async(func(ctx context.Context) (Node, error) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return Text("x"), nil
+		}))
+	}
+
+	html, err := RenderAsync(context.Background(), Div(nil, children...), AsyncOptions{Workers: 3})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<div>xxxxxxxxxxxx</div>", html)
+	assert.LessOrEqual(t, peak, int32(3))
+}
+
+func TestRenderAsyncNested(t *testing.T) {
+	inner := Async(func(ctx context.Context) (Node, error) {
+		return Span(nil, Text("inner")), nil
+	})
+	outer := Async(func(ctx context.Context) (Node, error) {
+		return Div(nil, Text("outer "), inner), nil
+	})
+
+	html, err := RenderAsync(context.Background(), Body(nil, outer), AsyncOptions{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<body><div>outer <span>inner</span></div></body>", html)
+}
+
+func TestRenderAsyncErrors(t *testing.T) {
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+	failing := func(err error) *AsyncNode {
+		return &AsyncNode{
+			Content:  func(ctx context.Context) (Node, error) { return nil, err },
+			Fallback: P(nil, Text("unavailable")),
+		}
+	}
+	panicking := Async(func(ctx context.Context) (Node, error) {
+		panic("boom")
+	})
+	ok := Async(func(ctx context.Context) (Node, error) { return Text("ok"), nil })
+
+	html, err := RenderAsync(context.Background(), Div(nil, failing(errFirst), ok, panicking, failing(errSecond)), AsyncOptions{})
+
+	assert.Equal(t, "<div><p>unavailable</p>ok<p>unavailable</p></div>", html)
+	assert.ErrorIs(t, err, errFirst)
+	assert.ErrorIs(t, err, errSecond)
+	assert.EqualError(t, err, "first\nelem: async content panicked: boom\nsecond")
+}
+
+func TestRenderAsyncCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var ran int32
+	content := func(ctx context.Context) (Node, error) {
+		atomic.AddInt32(&ran, 1)
+		return Text("x"), nil
+	}
+
+	html, err := RenderAsync(ctx, Div(nil, Async(content), Async(content)), AsyncOptions{})
+
+	assert.Equal(t, "<div></div>", html)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, "context canceled", "The context error should be reported once")
+	assert.Equal(t, int32(0), ran)
+}
@@ -94,6 +94,13 @@ const (
 
 	Open = "open"
 
+	// Template Attributes (declarative shadow DOM)
+
+	Shadowrootmode           = "shadowrootmode"
+	Shadowrootdelegatesfocus = "shadowrootdelegatesfocus"
+	Shadowrootclonable       = "shadowrootclonable"
+	Shadowrootserializable   = "shadowrootserializable"
+
 	// Area-Specific Attributes
 	Shape  = "shape"
 	Coords = "coords"
@@ -156,6 +163,7 @@ const (
 	AriaBusy             = "aria-busy"
 	AriaChecked          = "aria-checked"
 	AriaControls         = "aria-controls"
+	AriaCurrent          = "aria-current"
 	AriaDescribedby      = "aria-describedby"
 	AriaDisabled         = "aria-disabled"
 	AriaExpanded         = "aria-expanded"
@@ -0,0 +1,178 @@
+// Command css2go converts a stylesheet to Go source declaring it as a
+// styles.ParsedCSS, to migrate legacy CSS files to a StyleManager:
+//
+//	css2go -pkg theme -var Legacy -o legacy_css.go legacy.css
+//
+// The generated variable is added to a StyleManager with AddParsedCSS, or
+// edited into composite styles. Constructs the parser skips are reported on
+// standard error. Without a file, the stylesheet is read from standard input.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/chasefleming/elem-go/styles"
+)
+
+func main() {
+	pkg := flag.String("pkg", "main", "package of the generated file")
+	name := flag.String("var", "Stylesheet", "name of the generated variable")
+	out := flag.String("o", "", "output file (default standard output)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: css2go [flags] [file.css]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *out, *pkg, *name); err != nil {
+		fmt.Fprintln(os.Stderr, "css2go:", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg, name string) error {
+	var src []byte
+	var err error
+	source := "standard input"
+	if in == "" {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		src, err = os.ReadFile(in)
+		source = filepath.Base(in)
+	}
+	if err != nil {
+		return err
+	}
+
+	css := styles.ParseCSS(string(src))
+	for _, w := range css.Warnings {
+		fmt.Fprintf(os.Stderr, "%s:%d: %s\n", source, w.Line, w.Message)
+	}
+	code, err := generate(css, pkg, name, source)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(out, code, 0o644)
+}
+
+// generate returns the formatted Go source declaring css as a variable.
+func generate(css *styles.ParsedCSS, pkg, name, source string) ([]byte, error) {
+	if !token.IsIdentifier(pkg) {
+		return nil, fmt.Errorf("invalid package name %q", pkg)
+	}
+	if !token.IsIdentifier(name) {
+		return nil, fmt.Errorf("invalid variable name %q", name)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by css2go from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import \"github.com/chasefleming/elem-go/styles\"\n\n")
+	fmt.Fprintf(&b, "// %s holds the rules of %s, to add to a StyleManager with AddParsedCSS.\n", name, source)
+	fmt.Fprintf(&b, "var %s = &styles.ParsedCSS{\n", name)
+
+	if len(css.Layers) > 0 {
+		fmt.Fprintf(&b, "Layers: %#v,\n", css.Layers)
+	}
+	if len(css.Imports) > 0 {
+		b.WriteString("Imports: []styles.Import{\n")
+		for _, imp := range css.Imports {
+			fmt.Fprintf(&b, "{URL: %q, Layer: %q, Supports: %q, Media: %q},\n", imp.URL, imp.Layer, imp.Supports, imp.Media)
+		}
+		b.WriteString("},\n")
+	}
+	if len(css.Properties) > 0 {
+		b.WriteString("Properties: []styles.CustomProperty{\n")
+		for _, p := range css.Properties {
+			fmt.Fprintf(&b, "{Name: %q, Syntax: %q, Inherits: %t, InitialValue: %q},\n", p.Name, p.Syntax, p.Inherits, p.InitialValue)
+		}
+		b.WriteString("},\n")
+	}
+	if len(css.FontFaces) > 0 {
+		b.WriteString("FontFaces: []styles.FontFace{\n")
+		for _, f := range css.FontFaces {
+			fmt.Fprintf(&b, "{Family: %q, Weight: %q, Style: %q, Display: %q, UnicodeRange: %q, Props: ", f.Family, f.Weight, f.Style, f.Display, f.UnicodeRange)
+			writeProps(&b, f.Props)
+			b.WriteString("},\n")
+		}
+		b.WriteString("},\n")
+	}
+	if len(css.Keyframes) > 0 {
+		b.WriteString("Keyframes: map[string]styles.Keyframes{\n")
+		for _, animation := range sortedKeys(css.Keyframes) {
+			fmt.Fprintf(&b, "%q: {\n", animation)
+			keyframes := css.Keyframes[animation]
+			for _, stop := range sortedKeys(keyframes) {
+				fmt.Fprintf(&b, "%q: ", stop)
+				writeProps(&b, keyframes[stop])
+				b.WriteString(",\n")
+			}
+			b.WriteString("},\n")
+		}
+		b.WriteString("},\n")
+	}
+	if len(css.Rules) > 0 {
+		b.WriteString("Rules: ")
+		writeRules(&b, css.Rules)
+		b.WriteString(",\n")
+	}
+	b.WriteString("}\n")
+
+	return format.Source([]byte(b.String()))
+}
+
+func writeRules(b *strings.Builder, rules []styles.Rule) {
+	b.WriteString("[]styles.Rule{\n")
+	for _, rule := range rules {
+		fmt.Fprintf(b, "{\nSelector: %q,\n", rule.Selector)
+		if len(rule.Props) > 0 {
+			b.WriteString("Props: ")
+			writeProps(b, rule.Props)
+			b.WriteString(",\n")
+		}
+		if len(rule.Rules) > 0 {
+			b.WriteString("Rules: ")
+			writeRules(b, rule.Rules)
+			b.WriteString(",\n")
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}")
+}
+
+func writeProps(b *strings.Builder, props styles.Props) {
+	if len(props) == 0 {
+		b.WriteString("nil")
+		return
+	}
+	b.WriteString("styles.Props{\n")
+	for _, property := range sortedKeys(props) {
+		fmt.Fprintf(b, "%q: %q,\n", property, props[property])
+	}
+	b.WriteString("}")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
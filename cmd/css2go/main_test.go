@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go/styles"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	css := styles.ParseCSS(`@layer base;
+@import "reset.css" layer(base);
+@property --size { syntax: "<length>"; inherits: true; initial-value: 1rem }
+@font-face { font-family: Inter; src: url(inter.woff2) format("woff2") }
+.btn { content: "\"quoted\""; &:hover { color: blue } }
+@keyframes fade { from { opacity: 0 } }
+`)
+	code, err := generate(css, "theme", "Legacy", "legacy.css")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by css2go from legacy.css. DO NOT EDIT.
+
+package theme
+
+import "github.com/chasefleming/elem-go/styles"
+
+// Legacy holds the rules of legacy.css, to add to a StyleManager with AddParsedCSS.
+var Legacy = &styles.ParsedCSS{
+	Layers: []string{"base"},
+	Imports: []styles.Import{
+		{URL: "reset.css", Layer: "base", Supports: "", Media: ""},
+	},
+	Properties: []styles.CustomProperty{
+		{Name: "size", Syntax: "<length>", Inherits: true, InitialValue: "1rem"},
+	},
+	FontFaces: []styles.FontFace{
+		{Family: "Inter", Weight: "", Style: "", Display: "", UnicodeRange: "", Props: styles.Props{
+			"src": "url(inter.woff2) format(\"woff2\")",
+		}},
+	},
+	Keyframes: map[string]styles.Keyframes{
+		"fade": {
+			"from": styles.Props{
+				"opacity": "0",
+			},
+		},
+	},
+	Rules: []styles.Rule{
+		{
+			Selector: ".btn",
+			Props: styles.Props{
+				"content": "\"\\\"quoted\\\"\"",
+			},
+			Rules: []styles.Rule{
+				{
+					Selector: "&:hover",
+					Props: styles.Props{
+						"color": "blue",
+					},
+				},
+			},
+		},
+	},
+}
+`, string(code))
+}
+
+func TestGenerateInvalidName(t *testing.T) {
+	_, err := generate(styles.ParseCSS(".a { color: red }"), "theme", "not valid", "a.css")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package elem
+
+import "strings"
+
+// When returns the node built by fn if condition is true, and None
+// otherwise. Unlike If, fn is only called when its node is needed, so it can
+// use values that are only valid when condition holds:
+//
+//	elem.When(user != nil, func() elem.Node { return Profile(user) })
+func When(condition bool, fn func() Node) Node {
+	if condition {
+		return fn()
+	}
+	return None()
+}
+
+// IfChain is a chain of conditions started with IfElse. It renders the node
+// of the first condition that holds, or nothing if none does and there is
+// no Else.
+type IfChain struct {
+	matched bool
+	node    Node
+}
+
+// IfElse starts a chain of conditions, each with a function building its
+// node. Only the function of the first condition that holds is called, but
+// like any arguments, all conditions are evaluated.
+//
+//	elem.IfElse(len(results) == 0, func() elem.Node { return NoResults() }).
+//		ElseIf(len(results) == 1, func() elem.Node { return Result(results[0]) }).
+//		Else(func() elem.Node { return ResultList(results) })
+func IfElse(condition bool, fn func() Node) IfChain {
+	return IfChain{}.ElseIf(condition, fn)
+}
+
+// ElseIf adds a condition that is checked if no previous one held.
+func (c IfChain) ElseIf(condition bool, fn func() Node) IfChain {
+	if c.matched || !condition {
+		return c
+	}
+	return IfChain{matched: true, node: fn()}
+}
+
+// Else ends the chain, returning the node of the condition that held or the
+// node built by fn if none did.
+func (c IfChain) Else(fn func() Node) Node {
+	if c.matched {
+		return c.node
+	}
+	return fn()
+}
+
+func (c IfChain) RenderTo(builder *strings.Builder, opts RenderOptions) {
+	if c.node != nil {
+		c.node.RenderTo(builder, opts)
+	}
+}
+
+func (c IfChain) Render() string {
+	return c.RenderWithOptions(RenderOptions{})
+}
+
+func (c IfChain) RenderWithOptions(opts RenderOptions) string {
+	if c.node == nil {
+		return ""
+	}
+	return c.node.RenderWithOptions(opts)
+}
+
+// SwitchCase is a case of Switch, created with Case or Default.
+type SwitchCase[T comparable] struct {
+	value     T
+	isDefault bool
+	fn        func() Node
+}
+
+// Case returns a case of Switch that matches value.
+func Case[T comparable](value T, fn func() Node) SwitchCase[T] {
+	return SwitchCase[T]{value: value, fn: fn}
+}
+
+// Default returns a case of Switch that is used when no other case matches,
+// wherever it appears. As it has no value, its type argument can't be
+// inferred and must be given, as in Default[Status].
+func Default[T comparable](fn func() Node) SwitchCase[T] {
+	return SwitchCase[T]{isDefault: true, fn: fn}
+}
+
+// Switch returns the node built by the first case matching value, or by the
+// default case if none does. The cases must have the same type as value, so
+// a mismatch such as an int64 value with int cases doesn't compile. Only the
+// function of the chosen case is called. Without a matching or default case,
+// Switch returns None.
+//
+//	elem.Switch(order.Status,
+//		elem.Case(StatusShipped, func() elem.Node { return Tracking(order) }),
+//		elem.Case(StatusCanceled, func() elem.Node { return Refund(order) }),
+//		elem.Default[Status](func() elem.Node { return elem.Text("Processing") }),
+//	)
+func Switch[T comparable](value T, cases ...SwitchCase[T]) Node {
+	var fallback *SwitchCase[T]
+	for i, c := range cases {
+		if c.isDefault {
+			if fallback == nil {
+				fallback = &cases[i]
+			}
+			continue
+		}
+		if c.value == value {
+			return c.fn()
+		}
+	}
+	if fallback != nil {
+		return fallback.fn()
+	}
+	return None()
+}
+
+// LazyNode is a node whose subtree is built by a function each time it is
+// rendered, e.g. to defer expensive work until it's known to be rendered, or
+// to render values that change after the tree is built. Walk and the
+// functions built on it don't look inside lazy nodes.
+type LazyNode func() Node
+
+// Lazy returns a node that calls fn when it is rendered and renders the
+// result.
+func Lazy(fn func() Node) LazyNode {
+	return LazyNode(fn)
+}
+
+func (l LazyNode) RenderTo(builder *strings.Builder, opts RenderOptions) {
+	if n := l(); n != nil {
+		n.RenderTo(builder, opts)
+	}
+}
+
+func (l LazyNode) Render() string {
+	return l.RenderWithOptions(RenderOptions{})
+}
+
+func (l LazyNode) RenderWithOptions(opts RenderOptions) string {
+	var builder strings.Builder
+	l.RenderTo(&builder, opts)
+	return builder.String()
+}
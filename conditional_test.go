@@ -0,0 +1,77 @@
+package elem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	name string
+}
+
+func TestWhen(t *testing.T) {
+	var nobody *user
+	profile := func(u *user) func() Node {
+		return func() Node { return Span(nil, Text(u.name)) }
+	}
+
+	assert.Equal(t, "<div></div>", Div(nil, When(nobody != nil, profile(nobody))).Render(), "When should not call fn when the condition is false")
+	assert.Equal(t, "<div><span>ada</span></div>", Div(nil, When(true, profile(&user{name: "ada"}))).Render())
+}
+
+func TestIfElse(t *testing.T) {
+	results := func(users []user) Node {
+		return IfElse(len(users) == 0, func() Node { return P(nil, Text("No users")) }).
+			ElseIf(len(users) == 1, func() Node { return P(nil, Text(users[0].name)) }).
+			Else(func() Node { return P(nil, Text("Many users")) })
+	}
+
+	assert.Equal(t, "<p>No users</p>", results(nil).Render())
+	assert.Equal(t, "<p>ada</p>", results([]user{{name: "ada"}}).Render())
+	assert.Equal(t, "<p>Many users</p>", results([]user{{name: "ada"}, {name: "bob"}}).Render())
+
+	calls := 0
+	chain := IfElse(false, func() Node { calls++; return Text("a") }).
+		ElseIf(true, func() Node { return Text("b") }).
+		ElseIf(true, func() Node { calls++; return Text("c") })
+	assert.Equal(t, "<p>b</p>", P(nil, chain).Render(), "The first condition that holds should win")
+	assert.Equal(t, "", IfElse(false, func() Node { return Text("a") }).Render(), "A chain without Else should render nothing if no condition holds")
+	assert.Equal(t, 0, calls)
+}
+
+func TestSwitch(t *testing.T) {
+	type status int
+	const (
+		active status = iota
+		banned
+		pending
+	)
+	badge := func(s status) Node {
+		return Switch(s,
+			Default[status](func() Node { return Text("unknown") }),
+			Case(active, func() Node { return Text("active") }),
+			Case(banned, func() Node { return Text("banned") }),
+		)
+	}
+
+	assert.Equal(t, "active", badge(active).Render())
+	assert.Equal(t, "banned", badge(banned).Render())
+	assert.Equal(t, "unknown", badge(pending).Render(), "Default should be used wherever it appears")
+	assert.Equal(t, "", Switch(pending, Case(active, func() Node { return Text("active") })).Render(), "Without a default, Switch should render nothing")
+}
+
+func TestLazy(t *testing.T) {
+	count := 0
+	counter := Lazy(func() Node {
+		count++
+		return Text("rendered")
+	})
+	page := Div(nil, counter)
+	assert.Equal(t, 0, count, "Lazy should not build its subtree until rendered")
+
+	assert.Equal(t, "<div>rendered</div>", page.Render())
+	assert.Equal(t, "<div>rendered</div>", page.Render())
+	assert.Equal(t, 2, count, "Lazy should build its subtree on every render")
+	assert.Equal(t, "", Lazy(func() Node { return nil }).Render())
+}
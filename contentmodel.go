@@ -0,0 +1,187 @@
+package elem
+
+import "strings"
+
+// The tables below describe the parts of the HTML content model checked by
+// Validate. See https://html.spec.whatwg.org/multipage/indices.html.
+
+// phrasingContent are the elements allowed where only phrasing content is.
+var phrasingContent = tagSet(`a abbr area audio b bdi bdo br button canvas cite code data datalist del
+	dfn em embed i iframe img input ins kbd label link map mark math meta meter noscript object output
+	picture progress q ruby s samp script select slot small span strong sub sup svg template textarea
+	time u var video wbr`)
+
+// phrasingParents are the elements whose content is limited to phrasing
+// content.
+var phrasingParents = tagSet(`abbr b bdi bdo button cite code data dfn em h1 h2 h3 h4 h5 h6 i kbd label
+	mark output p pre progress meter q rp rt s samp small span strong sub sup time u var`)
+
+// allowedChildren restricts the child elements of elements with a fixed
+// content model. Text other than whitespace isn't allowed in them either.
+// Script and template are allowed everywhere and not listed.
+var allowedChildren = map[string]map[string]struct{}{
+	"ul":       tagSet("li"),
+	"ol":       tagSet("li"),
+	"menu":     tagSet("li"),
+	"dl":       tagSet("dt dd div"),
+	"table":    tagSet("caption colgroup thead tbody tfoot tr"),
+	"thead":    tagSet("tr"),
+	"tbody":    tagSet("tr"),
+	"tfoot":    tagSet("tr"),
+	"tr":       tagSet("td th"),
+	"colgroup": tagSet("col"),
+	"select":   tagSet("option optgroup hr"),
+	"optgroup": tagSet("option"),
+	"datalist": tagSet("option"),
+	"picture":  tagSet("source img"),
+	"head":     tagSet("base link meta noscript style title"),
+	"html":     tagSet("head body"),
+}
+
+// requiredParents lists the elements that are only valid as children of
+// particular elements. Tr is valid in table too, but the browser then wraps
+// it in an implied tbody; Validate reports that separately.
+var requiredParents = map[string]map[string]struct{}{
+	"li":         tagSet("ul ol menu"),
+	"dt":         tagSet("dl div"),
+	"dd":         tagSet("dl div"),
+	"tr":         tagSet("thead tbody tfoot"),
+	"td":         tagSet("tr"),
+	"th":         tagSet("tr"),
+	"thead":      tagSet("table"),
+	"tbody":      tagSet("table"),
+	"tfoot":      tagSet("table"),
+	"caption":    tagSet("table"),
+	"colgroup":   tagSet("table"),
+	"col":        tagSet("colgroup"),
+	"option":     tagSet("select datalist optgroup"),
+	"optgroup":   tagSet("select"),
+	"legend":     tagSet("fieldset"),
+	"figcaption": tagSet("figure"),
+	"summary":    tagSet("details"),
+	"source":     tagSet("audio video picture"),
+	"track":      tagSet("audio video"),
+	"head":       tagSet("html"),
+	"body":       tagSet("html"),
+	"rt":         tagSet("ruby"),
+	"rp":         tagSet("ruby"),
+}
+
+// knownElements are the HTML elements whose attributes Validate checks.
+var knownElements = tagSet(`a abbr address area article aside audio b base bdi bdo blockquote body br
+	button canvas caption cite code col colgroup data datalist dd del details dfn dialog div dl dt em
+	embed fieldset figcaption figure footer form h1 h2 h3 h4 h5 h6 head header hgroup hr html i iframe
+	img input ins kbd label legend li link main map mark menu meta meter nav noscript object ol
+	optgroup option output p picture pre progress q rp rt ruby s samp script search section select
+	slot small source span strong style sub summary sup table tbody td template textarea tfoot th
+	thead time title tr track u ul var video wbr`)
+
+// attrElements maps the element-specific HTML attributes to the elements
+// that accept them. Global attributes and attributes not listed here, such
+// as data-*, aria-* and hx-*, are accepted on every element.
+var attrElements = map[string]map[string]struct{}{
+	"accept":              tagSet("input"),
+	"accept-charset":      tagSet("form"),
+	"action":              tagSet("form"),
+	"allow":               tagSet("iframe"),
+	"allowfullscreen":     tagSet("iframe"),
+	"alt":                 tagSet("area img input"),
+	"as":                  tagSet("link"),
+	"async":               tagSet("script"),
+	"autocomplete":        tagSet("form input select textarea"),
+	"autoplay":            tagSet("audio video"),
+	"charset":             tagSet("meta"),
+	"checked":             tagSet("input"),
+	"cite":                tagSet("blockquote del ins q"),
+	"cols":                tagSet("textarea"),
+	"colspan":             tagSet("td th"),
+	"content":             tagSet("meta"),
+	"controls":            tagSet("audio video"),
+	"coords":              tagSet("area"),
+	"crossorigin":         tagSet("audio img link script video"),
+	"datetime":            tagSet("del ins time"),
+	"decoding":            tagSet("img"),
+	"default":             tagSet("track"),
+	"defer":               tagSet("script"),
+	"dirname":             tagSet("input textarea"),
+	"disabled":            tagSet("button fieldset input link optgroup option select textarea"),
+	"download":            tagSet("a area"),
+	"enctype":             tagSet("form"),
+	"for":                 tagSet("label output"),
+	"form":                tagSet("button fieldset input object output select textarea"),
+	"formaction":          tagSet("button input"),
+	"formenctype":         tagSet("button input"),
+	"formmethod":          tagSet("button input"),
+	"formnovalidate":      tagSet("button input"),
+	"formtarget":          tagSet("button input"),
+	"headers":             tagSet("td th"),
+	"height":              tagSet("canvas embed iframe img input object source video"),
+	"high":                tagSet("meter"),
+	"href":                tagSet("a area base link"),
+	"hreflang":            tagSet("a link"),
+	"http-equiv":          tagSet("meta"),
+	"integrity":           tagSet("link script"),
+	"ismap":               tagSet("img"),
+	"kind":                tagSet("track"),
+	"label":               tagSet("optgroup option track"),
+	"list":                tagSet("input"),
+	"loading":             tagSet("iframe img"),
+	"loop":                tagSet("audio video"),
+	"low":                 tagSet("meter"),
+	"max":                 tagSet("input meter progress"),
+	"maxlength":           tagSet("input textarea"),
+	"media":               tagSet("link meta source style"),
+	"method":              tagSet("form"),
+	"min":                 tagSet("input meter"),
+	"minlength":           tagSet("input textarea"),
+	"multiple":            tagSet("input select"),
+	"muted":               tagSet("audio video"),
+	"name":                tagSet("button details fieldset form iframe input map meta object output select slot textarea"),
+	"nomodule":            tagSet("script"),
+	"novalidate":          tagSet("form"),
+	"open":                tagSet("details dialog"),
+	"optimum":             tagSet("meter"),
+	"pattern":             tagSet("input"),
+	"ping":                tagSet("a area"),
+	"placeholder":         tagSet("input textarea"),
+	"playsinline":         tagSet("video"),
+	"popovertarget":       tagSet("button input"),
+	"popovertargetaction": tagSet("button input"),
+	"poster":              tagSet("video"),
+	"preload":             tagSet("audio video"),
+	"readonly":            tagSet("input textarea"),
+	"referrerpolicy":      tagSet("a area iframe img link script"),
+	"rel":                 tagSet("a area form link"),
+	"required":            tagSet("input select textarea"),
+	"reversed":            tagSet("ol"),
+	"rows":                tagSet("textarea"),
+	"rowspan":             tagSet("td th"),
+	"sandbox":             tagSet("iframe"),
+	"scope":               tagSet("th"),
+	"selected":            tagSet("option"),
+	"shadowrootmode":      tagSet("template"),
+	"shape":               tagSet("area"),
+	"size":                tagSet("input select"),
+	"sizes":               tagSet("img link source"),
+	"span":                tagSet("col colgroup"),
+	"src":                 tagSet("audio embed iframe img input script source track video"),
+	"srcdoc":              tagSet("iframe"),
+	"srclang":             tagSet("track"),
+	"srcset":              tagSet("img source"),
+	"start":               tagSet("ol"),
+	"step":                tagSet("input"),
+	"target":              tagSet("a area base form"),
+	"type":                tagSet("a button embed input link object ol script source"),
+	"usemap":              tagSet("img"),
+	"value":               tagSet("button data input li meter option output progress"),
+	"width":               tagSet("canvas embed iframe img input object source video"),
+	"wrap":                tagSet("textarea"),
+}
+
+func tagSet(names string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, name := range strings.Fields(names) {
+		set[name] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,110 @@
+package elem
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chasefleming/elem-go/attrs"
+)
+
+// reservedCustomElementNames are hyphenated names that predate custom
+// elements and therefore can't be used for them.
+// See https://html.spec.whatwg.org/multipage/custom-elements.html#valid-custom-element-name
+var reservedCustomElementNames = map[string]struct{}{
+	"annotation-xml":   {},
+	"color-profile":    {},
+	"font-face":        {},
+	"font-face-src":    {},
+	"font-face-uri":    {},
+	"font-face-format": {},
+	"font-face-name":   {},
+	"missing-glyph":    {},
+}
+
+// NewElement creates an element with an arbitrary tag name. It is the
+// generic form of the constructors in elements.go, for tags that don't have
+// one of their own. Whether the element is rendered as a void element follows
+// the built-in list plus anything added with RegisterVoidElement.
+func NewElement(tag string, attrs attrs.Props, children ...Node) *Element {
+	return newElement(tag, attrs, children...)
+}
+
+// CustomElement creates an autonomous custom element such as <my-widget>,
+// the tag of a web component. It panics if name is not a valid custom
+// element name, since that is a programming error; use
+// ValidateCustomElementName to check names that come from elsewhere.
+func CustomElement(name string, attrs attrs.Props, children ...Node) *Element {
+	if err := ValidateCustomElementName(name); err != nil {
+		panic(err)
+	}
+	return newElement(name, attrs, children...)
+}
+
+// ValidateCustomElementName reports whether name is a valid custom element
+// name: it must start with a lowercase ASCII letter, contain a hyphen, contain
+// no uppercase ASCII letters and not be one of the reserved names such as
+// "font-face".
+func ValidateCustomElementName(name string) error {
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		return fmt.Errorf("elem: custom element name %q must start with a lowercase ASCII letter", name)
+	}
+	if !strings.Contains(name, "-") {
+		return fmt.Errorf("elem: custom element name %q must contain a hyphen", name)
+	}
+	if _, reserved := reservedCustomElementNames[name]; reserved {
+		return fmt.Errorf("elem: custom element name %q is reserved", name)
+	}
+	for _, r := range name {
+		if !isPotentialCustomElementNameChar(r) {
+			return fmt.Errorf("elem: custom element name %q contains invalid character %q", name, r)
+		}
+	}
+	return nil
+}
+
+// isPotentialCustomElementNameChar implements the PCENChar production of the
+// HTML specification.
+func isPotentialCustomElementNameChar(r rune) bool {
+	switch {
+	case r == '-' || r == '.' || r == '_' || r == 0xB7:
+		return true
+	case r >= '0' && r <= '9', r >= 'a' && r <= 'z':
+		return true
+	case r >= 0xC0 && r <= 0xD6, r >= 0xD8 && r <= 0xF6, r >= 0xF8 && r <= 0x37D,
+		r >= 0x37F && r <= 0x1FFF, r >= 0x200C && r <= 0x200D, r >= 0x203F && r <= 0x2040,
+		r >= 0x2070 && r <= 0x218F, r >= 0x2C00 && r <= 0x2FEF, r >= 0x3001 && r <= 0xD7FF,
+		r >= 0xF900 && r <= 0xFDCF, r >= 0xFDF0 && r <= 0xFFFD, r >= 0x10000 && r <= 0xEFFFF:
+		return true
+	}
+	return false
+}
+
+// RegisterVoidElement marks tag as a void element, so it renders without
+// children or a closing tag. Browsers never parse custom elements as void:
+// a <my-el> rendered without its end tag wraps the siblings that follow it,
+// so only register tags that HTML parsers know to be void. Registration is
+// not synchronized with rendering and should happen during program
+// initialization, e.g. from an init func.
+func RegisterVoidElement(tag string) {
+	voidElements[tag] = struct{}{}
+}
+
+// IsVoidElement reports whether tag is rendered as a void element.
+func IsVoidElement(tag string) bool {
+	_, exists := voidElements[tag]
+	return exists
+}
+
+// RegisterBooleanAttr marks name as a boolean attribute, so a value of "true"
+// renders the bare attribute name and any other value omits the attribute.
+// Like RegisterVoidElement, it should be called during program
+// initialization.
+func RegisterBooleanAttr(name string) {
+	booleanAttrs[name] = struct{}{}
+}
+
+// IsBooleanAttr reports whether name is rendered as a boolean attribute.
+func IsBooleanAttr(name string) bool {
+	_, exists := booleanAttrs[name]
+	return exists
+}
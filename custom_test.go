@@ -0,0 +1,59 @@
+package elem
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewElement(t *testing.T) {
+	expected := `<search-box role="search"><p>Hi</p></search-box>`
+	el := NewElement("search-box", attrs.Props{attrs.Role: "search"}, P(nil, Text("Hi")))
+	assert.Equal(t, expected, el.Render())
+
+	// Built-in void elements keep their rules.
+	assert.Equal(t, `<img src="a.png">`, NewElement("img", attrs.Props{attrs.Src: "a.png"}).Render())
+}
+
+func TestCustomElement(t *testing.T) {
+	expected := `<my-widget size="large">Content</my-widget>`
+	el := CustomElement("my-widget", attrs.Props{"size": "large"}, Text("Content"))
+	assert.Equal(t, expected, el.Render())
+
+	assert.Panics(t, func() { CustomElement("widget", nil) })
+}
+
+func TestValidateCustomElementName(t *testing.T) {
+	valid := []string{"my-widget", "x-1", "math-α", "emotion-😍", "a-b.c_d"}
+	for _, name := range valid {
+		assert.NoError(t, ValidateCustomElementName(name), name)
+	}
+
+	invalid := []string{"", "widget", "My-widget", "my-Widget", "1-widget", "-widget", "font-face", "my widget", "my-widget!"}
+	for _, name := range invalid {
+		assert.Error(t, ValidateCustomElementName(name), name)
+	}
+}
+
+func TestRegisterVoidElement(t *testing.T) {
+	t.Cleanup(func() { delete(voidElements, "my-icon") })
+
+	assert.False(t, IsVoidElement("my-icon"))
+	RegisterVoidElement("my-icon")
+	assert.True(t, IsVoidElement("my-icon"))
+
+	el := CustomElement("my-icon", attrs.Props{"name": "star"})
+	assert.Equal(t, `<my-icon name="star">`, el.Render())
+}
+
+func TestRegisterBooleanAttr(t *testing.T) {
+	t.Cleanup(func() { delete(booleanAttrs, "collapsed") })
+
+	assert.False(t, IsBooleanAttr("collapsed"))
+	RegisterBooleanAttr("collapsed")
+	assert.True(t, IsBooleanAttr("collapsed"))
+
+	assert.Equal(t, `<my-panel collapsed></my-panel>`, CustomElement("my-panel", attrs.Props{"collapsed": "true"}).Render())
+	assert.Equal(t, `<my-panel></my-panel>`, CustomElement("my-panel", attrs.Props{"collapsed": "false"}).Render())
+}
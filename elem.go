@@ -53,6 +53,10 @@ var booleanAttrs = map[string]struct{}{
 	attrs.Readonly:        {},
 	attrs.Required:        {},
 	attrs.Selected:        {},
+
+	attrs.Shadowrootclonable:       {},
+	attrs.Shadowrootdelegatesfocus: {},
+	attrs.Shadowrootserializable:   {},
 }
 
 type CSSGenerator interface {
@@ -63,6 +67,58 @@ type RenderOptions struct {
 	// DisableHtmlPreamble disables the doctype preamble for the HTML tag if it exists in the rendering tree
 	DisableHtmlPreamble bool
 	StyleManager        CSSGenerator
+	// Hook, if set, is handed the root of the tree before RenderWithOptions
+	// renders it, e.g. to run development-time checks on the whole document.
+	Hook RenderHook
+	// Minify makes the output smaller in ways browsers parse and render the
+	// same: attribute values are unquoted where possible, optional end tags
+	// such as </li> and </p> are left out, runs of whitespace in text are
+	// collapsed outside pre and textarea, and the StyleManager's CSS is
+	// minified if it implements MinifiedCSSGenerator. Text styled with CSS
+	// white-space rules that preserve spaces should be put in a pre.
+	Minify bool
+	// TreeShakeCSS leaves out the CSS of the StyleManager's classes that
+	// aren't in the class attribute of any rendered element, if it
+	// implements UsedCSSGenerator, so each page only gets the CSS it uses.
+	// Classes that are only added by scripts or that are in raw HTML are
+	// not seen, so their styles must be rendered separately.
+	TreeShakeCSS bool
+
+	// keepWhitespace is set while rendering the content of the elements in
+	// which Minify leaves text alone.
+	keepWhitespace bool
+	// async holds the content of async nodes run by RenderAsync, and stream
+	// the state of RenderStream.
+	async  *asyncResults
+	stream *stream
+	// usedClasses collects the classes of the rendered elements for
+	// TreeShakeCSS.
+	usedClasses *classSet
+}
+
+// RenderHook inspects a tree before it is rendered.
+type RenderHook interface {
+	BeforeRender(root Node)
+}
+
+// RenderHookFunc adapts a function to the RenderHook interface.
+type RenderHookFunc func(root Node)
+
+// BeforeRender calls f(root).
+func (f RenderHookFunc) BeforeRender(root Node) {
+	f(root)
+}
+
+// Hooks combines several render hooks into one that runs them in order.
+// Nil hooks are skipped.
+func Hooks(hooks ...RenderHook) RenderHook {
+	return RenderHookFunc(func(root Node) {
+		for _, h := range hooks {
+			if h != nil {
+				h.BeforeRender(root)
+			}
+		}
+	})
 }
 
 type Node interface {
@@ -92,9 +148,13 @@ func (n NoneNode) RenderWithOptions(opts RenderOptions) string {
 type TextNode string
 
 func (t TextNode) RenderTo(builder *strings.Builder, opts RenderOptions) {
+	s := string(t)
+	if opts.Minify && !opts.keepWhitespace {
+		s = collapseWhitespace(s)
+	}
 	// Stream the escaped text directly into the builder to avoid
 	// allocating an intermediate escaped string.
-	nodeContentReplacer.WriteString(builder, string(t))
+	nodeContentReplacer.WriteString(builder, s)
 }
 
 func (t TextNode) Render() string {
@@ -102,6 +162,9 @@ func (t TextNode) Render() string {
 }
 
 func (t TextNode) RenderWithOptions(opts RenderOptions) string {
+	if opts.Minify && !opts.keepWhitespace {
+		return EscapeNodeContents(collapseWhitespace(string(t)))
+	}
 	return EscapeNodeContents(string(t))
 }
 
@@ -185,6 +248,11 @@ type Element struct {
 }
 
 func (e *Element) RenderTo(builder *strings.Builder, opts RenderOptions) {
+	e.renderTo(builder, opts, false)
+}
+
+// renderTo renders e, leaving out its end tag if omitEndTag is set.
+func (e *Element) renderTo(builder *strings.Builder, opts RenderOptions, omitEndTag bool) {
 	// The HTML tag needs a doctype preamble in order to ensure
 	// browsers don't render in legacy/quirks mode
 	// https://developer.mozilla.org/en-US/docs/Glossary/Doctype
@@ -194,6 +262,12 @@ func (e *Element) RenderTo(builder *strings.Builder, opts RenderOptions) {
 
 	isFragment := e.Tag == "fragment"
 
+	if opts.usedClasses != nil {
+		if class, ok := e.Attrs[attrs.Class]; ok {
+			opts.usedClasses.add(class)
+		}
+	}
+
 	// Start with opening tag
 	if !isFragment {
 		builder.WriteString("<")
@@ -206,7 +280,7 @@ func (e *Element) RenderTo(builder *strings.Builder, opts RenderOptions) {
 	case 0:
 	case 1:
 		for k, v := range e.Attrs {
-			renderAttrTo(k, v, builder)
+			renderAttrTo(k, v, builder, opts.Minify)
 		}
 	default:
 		// Use a stack-allocated array for typical attribute counts so
@@ -223,7 +297,7 @@ func (e *Element) RenderTo(builder *strings.Builder, opts RenderOptions) {
 		}
 		slices.Sort(keys)
 		for _, k := range keys {
-			renderAttrTo(k, e.Attrs[k], builder)
+			renderAttrTo(k, e.Attrs[k], builder, opts.Minify)
 		}
 	}
 
@@ -239,11 +313,15 @@ func (e *Element) RenderTo(builder *strings.Builder, opts RenderOptions) {
 	}
 
 	// Build the content
-	for _, child := range e.Children {
-		child.RenderTo(builder, opts)
+	if opts.Minify && !isFragment {
+		e.renderMinifiedChildren(builder, opts)
+	} else {
+		for _, child := range e.Children {
+			child.RenderTo(builder, opts)
+		}
 	}
 
-	if !isFragment {
+	if !isFragment && !omitEndTag {
 		// Append closing tag
 		builder.WriteString(`</`)
 		builder.WriteString(e.Tag)
@@ -252,13 +330,21 @@ func (e *Element) RenderTo(builder *strings.Builder, opts RenderOptions) {
 }
 
 // return string representation of given attribute with its value
-func renderAttrTo(attrName, attrVal string, builder *strings.Builder) {
+func renderAttrTo(attrName, attrVal string, builder *strings.Builder, minify bool) {
 	if _, exists := booleanAttrs[attrName]; exists {
 		// boolean attribute presents its name only if the value is "true"
 		if attrVal == "true" {
 			builder.WriteString(` `)
 			builder.WriteString(attrName)
 		}
+	} else if minify && (attrVal == "" || canUnquote(attrVal)) {
+		// An empty attribute is the same as an empty value
+		builder.WriteString(` `)
+		builder.WriteString(attrName)
+		if attrVal != "" {
+			builder.WriteString(`=`)
+			builder.WriteString(attrVal)
+		}
 	} else {
 		// A necessary check to to avoid adding extra quotes around values that are already single-quoted
 		// An example is '{"quantity": 5}'
@@ -295,19 +381,31 @@ func (e *Element) estimateSize() int {
 			size += len(c)
 		case RawNode:
 			size += len(c)
+		case *FrozenNode:
+			size += len(c.variants[0].html)
 		}
 	}
 	return size
 }
 
 func (e *Element) RenderWithOptions(opts RenderOptions) string {
+	if opts.Hook != nil {
+		opts.Hook.BeforeRender(e)
+	}
+
+	if opts.TreeShakeCSS && opts.usedClasses == nil {
+		if _, ok := opts.StyleManager.(UsedCSSGenerator); ok {
+			opts.usedClasses = newClassSet()
+		}
+	}
+
 	var builder strings.Builder
 	builder.Grow(e.estimateSize())
 	e.RenderTo(&builder, opts)
 
 	if opts.StyleManager != nil {
 		htmlContent := builder.String()
-		cssContent := opts.StyleManager.GenerateCSS()
+		cssContent := generateCSS(opts.StyleManager, opts)
 
 		// Define the <style> element with the generated CSS content
 		styleElement := fmt.Sprintf("<style>%s</style>", cssContent)
@@ -3,6 +3,7 @@ package elem
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/chasefleming/elem-go/attrs"
 	"github.com/chasefleming/elem-go/styles"
@@ -108,3 +109,56 @@ func BenchmarkBuildAndRenderPage(b *testing.B) {
 		page.Render()
 	}
 }
+
+// staticLayout builds the parts of a page that are the same on every
+// request.
+func staticLayout() (nav, footer *Element) {
+	links := make([]int, 20)
+	nav = Nav(attrs.Props{attrs.Class: "site-nav"},
+		Ul(nil, TransformEach(links, func(i int) Node {
+			return Li(attrs.Props{attrs.Class: "nav-item"},
+				A(attrs.Props{attrs.Href: fmt.Sprintf("/section/%d", i)}, Text(fmt.Sprintf("Section %d & more", i))),
+			)
+		})...),
+	)
+	footer = Footer(attrs.Props{attrs.Class: "site-footer"},
+		TransformEach(links, func(i int) Node {
+			return P(nil, Text(fmt.Sprintf("Footer text <%d> with \"escaping\"", i)))
+		})...,
+	)
+	return nav, footer
+}
+
+func BenchmarkRenderStaticLayout(b *testing.B) {
+	nav, footer := staticLayout()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Body(nil, nav, Main(nil, Text("content")), footer).Render()
+	}
+}
+
+func BenchmarkRenderFrozenLayout(b *testing.B) {
+	nav, footer := staticLayout()
+	frozenNav, frozenFooter := Freeze(nav), Freeze(footer)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Body(nil, frozenNav, Main(nil, Text("content")), frozenFooter).Render()
+	}
+}
+
+func BenchmarkRenderMemoizedLayout(b *testing.B) {
+	memo := NewMemo[string](time.Minute)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			nav := memo.Get("nav", func() Node {
+				nav, _ := staticLayout()
+				return nav
+			})
+			Body(nil, nav, Main(nil, Text("content"))).Render()
+		}
+	})
+}
@@ -38,3 +38,15 @@ func TestRenderWithOptionsInjectsCSSIntoHead(t *testing.T) {
 	// Use testify's assert.Equal to check if the HTML output matches the expected HTML
 	assert.Equal(t, expectedHTML, htmlOutput, "The generated HTML should include the CSS in the <head> section")
 }
+
+func TestRenderWithOptionsCallsHook(t *testing.T) {
+	e := Div(nil, P(nil, Text("Hi")))
+
+	var seen []Node
+	html := e.RenderWithOptions(RenderOptions{
+		Hook: RenderHookFunc(func(root Node) { seen = append(seen, root) }),
+	})
+
+	assert.Equal(t, "<div><p>Hi</p></div>", html)
+	assert.Equal(t, []Node{e}, seen, "The hook should be called once with the root element")
+}
@@ -0,0 +1,156 @@
+// Package elemtest provides test helpers for elem trees: assertions based on
+// CSS selectors, HTML comparison that ignores attribute order and
+// insignificant whitespace, and golden-file snapshots.
+//
+// Failures include the tree printed with Pretty, and comparisons include a
+// line diff, so it is clear what was rendered.
+package elemtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/internal/htmlparse"
+)
+
+// AssertHas checks that the tree rooted at node contains an element
+// matching selector and returns the first match, or nil after failing the
+// test.
+func AssertHas(t testing.TB, node elem.Node, selector string) *elem.Element {
+	t.Helper()
+	matches, ok := findAll(t, node, selector)
+	if !ok {
+		return nil
+	}
+	if len(matches) == 0 {
+		t.Errorf("no element matches %q in:\n%s", selector, Pretty(node))
+		return nil
+	}
+	return matches[0]
+}
+
+// AssertNotHas checks that no element in the tree rooted at node matches
+// selector.
+func AssertNotHas(t testing.TB, node elem.Node, selector string) bool {
+	t.Helper()
+	matches, ok := findAll(t, node, selector)
+	if !ok {
+		return false
+	}
+	if len(matches) > 0 {
+		t.Errorf("expected no element to match %q, found %d:\n%s", selector, len(matches), prettyAll(matches))
+		return false
+	}
+	return true
+}
+
+// AssertCount checks that exactly want elements in the tree rooted at node
+// match selector.
+func AssertCount(t testing.TB, node elem.Node, selector string, want int) bool {
+	t.Helper()
+	matches, ok := findAll(t, node, selector)
+	if !ok {
+		return false
+	}
+	if len(matches) != want {
+		t.Errorf("expected %d elements to match %q, found %d in:\n%s", want, selector, len(matches), Pretty(node))
+		return false
+	}
+	return true
+}
+
+// AssertAttr checks that the first element matching selector has the
+// attribute attr set to want.
+func AssertAttr(t testing.TB, node elem.Node, selector, attr, want string) bool {
+	t.Helper()
+	e := AssertHas(t, node, selector)
+	if e == nil {
+		return false
+	}
+	got, exists := e.Attrs[attr]
+	if !exists {
+		t.Errorf("%q has no %s attribute:\n%s", selector, attr, Pretty(e))
+		return false
+	}
+	if got != want {
+		t.Errorf("%q has %s=%q, want %q:\n%s", selector, attr, got, want, Pretty(e))
+		return false
+	}
+	return true
+}
+
+// AssertTextContains checks that the text content of the first element
+// matching selector contains substr.
+func AssertTextContains(t testing.TB, node elem.Node, selector, substr string) bool {
+	t.Helper()
+	e := AssertHas(t, node, selector)
+	if e == nil {
+		return false
+	}
+	if text := TextContent(e); !strings.Contains(text, substr) {
+		t.Errorf("text of %q is %q, which doesn't contain %q", selector, text, substr)
+		return false
+	}
+	return true
+}
+
+// AssertHTMLEqual checks that node renders markup equivalent to want,
+// comparing both after NormalizeHTML. To compare two HTML strings, pass the
+// second one as elem.Raw(s).
+func AssertHTMLEqual(t testing.TB, want string, node elem.Node) bool {
+	t.Helper()
+	wantNorm, gotNorm := NormalizeHTML(want), NormalizeHTML(node.Render())
+	if wantNorm != gotNorm {
+		t.Errorf("HTML differs (-want +got):\n%s", lineDiff(wantNorm, gotNorm))
+		return false
+	}
+	return true
+}
+
+// NormalizeHTML parses markup and prints it back with Pretty, so that two
+// documents that differ only in attribute order, quoting, character
+// references, optional end tags or whitespace between and around text
+// normalize to the same string. Whitespace inside pre, textarea, script and
+// style is preserved.
+func NormalizeHTML(markup string) string {
+	nodes := collapseWhitespace(htmlparse.Parse(markup))
+	return Pretty(elem.Fragment(htmlparse.ToElem(nodes)...))
+}
+
+// collapseWhitespace collapses runs of whitespace in text nodes to a single
+// space, trims them and drops the ones that end up empty.
+func collapseWhitespace(nodes []*htmlparse.Node) []*htmlparse.Node {
+	out := nodes[:0]
+	for _, n := range nodes {
+		switch {
+		case n.Type == htmlparse.TextNode:
+			n.Data = strings.Join(strings.Fields(n.Data), " ")
+			if n.Data == "" {
+				continue
+			}
+		case n.Type == htmlparse.ElementNode && !preformatted[n.Tag]:
+			n.Children = collapseWhitespace(n.Children)
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func findAll(t testing.TB, node elem.Node, selector string) ([]*elem.Element, bool) {
+	t.Helper()
+	sel, err := compileSelector(selector)
+	if err != nil {
+		t.Errorf("%v", err)
+		return nil, false
+	}
+	return sel.findAll(node), true
+}
+
+func prettyAll(elements []*elem.Element) string {
+	var b strings.Builder
+	for _, e := range elements {
+		b.WriteString(Pretty(e))
+	}
+	return b.String()
+}
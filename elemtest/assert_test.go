@@ -0,0 +1,79 @@
+package elemtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a testing.TB that records failures instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func TestAssertionsPass(t *testing.T) {
+	assert.NotNil(t, AssertHas(t, page, "nav a.active"))
+	AssertNotHas(t, page, "table")
+	AssertCount(t, page, "input", 2)
+	AssertAttr(t, page, "nav a", attrs.Href, "/")
+	AssertTextContains(t, page, "p", "Hello wor")
+}
+
+func TestAssertionsFail(t *testing.T) {
+	r := &recorder{TB: t}
+
+	assert.Nil(t, AssertHas(r, page, "table"))
+	assert.False(t, AssertNotHas(r, page, "a"))
+	assert.False(t, AssertCount(r, page, "a", 3))
+	assert.False(t, AssertAttr(r, page, "nav a", attrs.Href, "/home"))
+	assert.False(t, AssertAttr(r, page, "nav a", attrs.Title, "x"))
+	assert.False(t, AssertTextContains(r, page, "p", "goodbye"))
+	assert.False(t, AssertCount(r, page, "a[", 1))
+
+	assert.Len(t, r.errors, 7)
+	assert.Contains(t, r.errors[0], `no element matches "table" in:`)
+	assert.Contains(t, r.errors[0], "  <nav class=\"menu main\">\n")
+	assert.Contains(t, r.errors[3], `"nav a" has href="/", want "/home"`)
+	assert.Contains(t, r.errors[6], `invalid selector "a["`)
+}
+
+func TestAssertHTMLEqual(t *testing.T) {
+	node := elem.Ul(attrs.Props{attrs.ID: "list", attrs.Class: "items"},
+		elem.Li(nil, elem.Text("Tom & Jerry")),
+		elem.Li(nil, elem.Text("Two")),
+	)
+	AssertHTMLEqual(t, `
+		<ul class=items id='list'>
+			<li>Tom &amp; Jerry
+			<li>  Two  </li>
+		</ul>`, node)
+
+	r := &recorder{TB: t}
+	assert.False(t, AssertHTMLEqual(r, `<ul class="items" id="list"><li>Tom &amp; Jerry</li><li>Three</li></ul>`, node))
+	assert.Equal(t, []string{"HTML differs (-want +got):\n" +
+		"  <ul class=\"items\" id=\"list\">\n" +
+		"    <li>Tom &amp; Jerry</li>\n" +
+		"-   <li>Three</li>\n" +
+		"+   <li>Two</li>\n" +
+		"  </ul>\n"}, r.errors)
+}
+
+func TestNormalizeHTML(t *testing.T) {
+	assert.Equal(t, "<p>a b</p>\n", NormalizeHTML("<p>\n  a\n  b\n</p>"))
+	assert.Equal(t, "<pre>  keep\n  this</pre>\n", NormalizeHTML("<pre>  keep\n  this</pre>"))
+	assert.Equal(t, NormalizeHTML(`<input disabled type=checkbox>`), NormalizeHTML(`<input type="checkbox" disabled="">`))
+}
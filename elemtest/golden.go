@@ -0,0 +1,56 @@
+package elemtest
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+)
+
+var update = flag.Bool("update", false, "update elemtest golden files instead of comparing against them")
+
+// GoldenDir is the directory golden files are read from and written to,
+// relative to the package under test.
+var GoldenDir = "testdata"
+
+// AssertGolden compares node, printed with Pretty, to the golden file
+// GoldenDir/name.golden. Run the tests with -update to create or overwrite
+// the file with the current output:
+//
+//	go test ./views -update
+//
+// The flag is only defined in test binaries that import elemtest, so name
+// those packages rather than passing it to every package with ./...
+func AssertGolden(t testing.TB, node elem.Node, name string) bool {
+	t.Helper()
+	path := filepath.Join(GoldenDir, name+".golden")
+	got := Pretty(node)
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("golden file %s does not exist; run the test with -update to create it", path)
+		return false
+	}
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	if string(want) != got {
+		t.Errorf("output differs from %s (-want +got):\n%s\nRun the test with -update to accept the new output.", path, lineDiff(string(want), got))
+		return false
+	}
+	return true
+}
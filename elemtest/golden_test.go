@@ -0,0 +1,49 @@
+package elemtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertGolden(t *testing.T) {
+	card := elem.Article(attrs.Props{attrs.Class: "card"},
+		elem.H2(nil, elem.Text("Title")),
+		elem.P(nil, elem.Text("Body")),
+	)
+	AssertGolden(t, card, "card")
+}
+
+func TestAssertGoldenMismatch(t *testing.T) {
+	if *update {
+		t.Skip("golden files are being updated")
+	}
+	r := &recorder{TB: t}
+
+	assert.False(t, AssertGolden(r, elem.P(nil, elem.Text("changed")), "card"))
+	assert.Contains(t, r.errors[0], "output differs from "+filepath.Join("testdata", "card.golden"))
+	assert.Contains(t, r.errors[0], "+ <p>changed</p>")
+
+	assert.False(t, AssertGolden(r, elem.P(nil), "missing"))
+	assert.Contains(t, r.errors[1], "does not exist")
+}
+
+func TestAssertGoldenUpdate(t *testing.T) {
+	dir := GoldenDir
+	GoldenDir = t.TempDir()
+	t.Cleanup(func() {
+		GoldenDir = dir
+		*update = false
+	})
+
+	*update = true
+	assert.True(t, AssertGolden(t, elem.P(nil, elem.Text("new")), "nested/new"))
+
+	written, err := os.ReadFile(filepath.Join(GoldenDir, "nested", "new.golden"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>new</p>\n", string(written))
+}
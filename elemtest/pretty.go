@@ -0,0 +1,79 @@
+package elemtest
+
+import (
+	"strings"
+
+	"github.com/chasefleming/elem-go"
+)
+
+// preformatted elements are printed exactly as rendered, since adding
+// indentation inside them would change their content.
+var preformatted = map[string]bool{
+	"pre": true, "textarea": true, "script": true, "style": true,
+}
+
+// Pretty renders node as indented HTML, one element per line, for readable
+// test failures and golden files. Elements whose only child is text are kept
+// on a single line. The output is meant for people and is not guaranteed to
+// be equivalent to Render, since the added whitespace can be significant.
+func Pretty(node elem.Node) string {
+	var b strings.Builder
+	pretty(&b, node, 0)
+	return b.String()
+}
+
+func pretty(b *strings.Builder, node elem.Node, depth int) {
+	e, ok := node.(*elem.Element)
+	if !ok || e == nil {
+		// Surrounding whitespace is dropped, since the indentation replaces it.
+		if out := strings.TrimSpace(node.Render()); out != "" {
+			writeLine(b, depth, out)
+		}
+		return
+	}
+	if e.Tag == "fragment" {
+		for _, child := range e.Children {
+			pretty(b, child, depth)
+		}
+		return
+	}
+
+	if e.Tag == "html" {
+		writeLine(b, depth, "<!DOCTYPE html>")
+	}
+	rendered := e.RenderWithOptions(elem.RenderOptions{DisableHtmlPreamble: true})
+	if elem.IsVoidElement(e.Tag) || preformatted[e.Tag] || !hasElementChild(e) {
+		writeLine(b, depth, rendered)
+		return
+	}
+
+	writeLine(b, depth, openingTag(e))
+	for _, child := range e.Children {
+		pretty(b, child, depth+1)
+	}
+	writeLine(b, depth, "</"+e.Tag+">")
+}
+
+// openingTag renders just the start tag of e, with its attributes.
+func openingTag(e *elem.Element) string {
+	shallow := &elem.Element{Tag: e.Tag, Attrs: e.Attrs}
+	rendered := shallow.RenderWithOptions(elem.RenderOptions{DisableHtmlPreamble: true})
+	return strings.TrimSuffix(rendered, "</"+e.Tag+">")
+}
+
+func hasElementChild(e *elem.Element) bool {
+	for _, child := range e.Children {
+		if _, ok := child.(*elem.Element); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func writeLine(b *strings.Builder, depth int, s string) {
+	for i := 0; i < depth; i++ {
+		b.WriteString("  ")
+	}
+	b.WriteString(s)
+	b.WriteByte('\n')
+}
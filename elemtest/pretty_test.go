@@ -0,0 +1,48 @@
+package elemtest
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPretty(t *testing.T) {
+	doc := elem.Html(attrs.Props{attrs.Lang: "en"},
+		elem.Head(nil, elem.Title(nil, elem.Text("Page"))),
+		elem.Body(nil,
+			elem.Comment("main"),
+			elem.P(nil, elem.Text("Hi "), elem.Em(nil, elem.Text("there"))),
+			elem.Pre(nil, elem.Text("a\n  b")),
+			elem.Br(nil),
+		),
+	)
+	expected := `<!DOCTYPE html>
+<html lang="en">
+  <head>
+    <title>Page</title>
+  </head>
+  <body>
+    <!-- main -->
+    <p>
+      Hi
+      <em>there</em>
+    </p>
+    <pre>a
+  b</pre>
+    <br>
+  </body>
+</html>
+`
+	assert.Equal(t, expected, Pretty(doc))
+}
+
+func TestPrettyFragment(t *testing.T) {
+	assert.Equal(t, "<p>a</p>\n<p>b</p>\n", Pretty(elem.Fragment(elem.P(nil, elem.Text("a")), elem.None(), elem.P(nil, elem.Text("b")))))
+}
+
+func TestLineDiff(t *testing.T) {
+	assert.Equal(t, "  a\n- b\n+ c\n  d\n", lineDiff("a\nb\nd\n", "a\nc\nd\n"))
+	assert.Equal(t, "  a\n+ b\n", lineDiff("a", "a\nb"))
+}
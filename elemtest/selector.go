@@ -0,0 +1,317 @@
+package elemtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chasefleming/elem-go"
+)
+
+// Find returns the first element in the tree rooted at node that matches
+// the CSS selector, or nil. Supported selectors are type (div), universal
+// (*), id (#main), class (.btn) and attribute selectors ([href],
+// [type=email], [class~=x], [href^=https], [href$=.pdf], [title*=word]),
+// compounds of those, the descendant and child (>) combinators, and
+// comma-separated lists. Find panics if the selector is invalid.
+func Find(node elem.Node, selector string) *elem.Element {
+	matches := FindAll(node, selector)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+// FindAll returns every element in the tree rooted at node that matches the
+// CSS selector, in document order. It panics if the selector is invalid.
+func FindAll(node elem.Node, selector string) []*elem.Element {
+	sel, err := compileSelector(selector)
+	if err != nil {
+		panic(err)
+	}
+	return sel.findAll(node)
+}
+
+// TextContent returns the concatenated text of the tree rooted at node, like
+// the DOM's textContent. Comments and script contents are left out.
+func TextContent(node elem.Node) string {
+	var b strings.Builder
+	elem.Walk(node, func(n elem.Node) bool {
+		switch n := n.(type) {
+		case elem.TextNode:
+			b.WriteString(string(n))
+		case elem.RawNode:
+			b.WriteString(string(n))
+		case *elem.Element:
+			return n.Tag != "script"
+		}
+		return true
+	})
+	return b.String()
+}
+
+// selector is a compiled comma-separated selector list.
+type selector []complexSelector
+
+// complexSelector is a chain of compound selectors joined by combinators,
+// stored right to left: parts[0] matches the element itself.
+type complexSelector []selectorPart
+
+type selectorPart struct {
+	compound compoundSelector
+	// child reports whether this part is joined to the part on its left
+	// (the next one in a complexSelector) by the child combinator rather
+	// than the descendant combinator.
+	child bool
+}
+
+type compoundSelector struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attrSelector
+}
+
+type attrSelector struct {
+	name, op, value string
+}
+
+func (s selector) findAll(node elem.Node) []*elem.Element {
+	var matches []*elem.Element
+	var ancestors []*elem.Element
+	var visit func(n elem.Node)
+	visit = func(n elem.Node) {
+		e, ok := n.(*elem.Element)
+		if !ok || e == nil {
+			return
+		}
+		if e.Tag == "fragment" {
+			for _, child := range e.Children {
+				visit(child)
+			}
+			return
+		}
+		for _, cs := range s {
+			if cs.matches(e, ancestors) {
+				matches = append(matches, e)
+				break
+			}
+		}
+		ancestors = append(ancestors, e)
+		for _, child := range e.Children {
+			visit(child)
+		}
+		ancestors = ancestors[:len(ancestors)-1]
+	}
+	visit(node)
+	return matches
+}
+
+// matches reports whether e, whose ancestors are listed from the root down,
+// matches the complex selector.
+func (cs complexSelector) matches(e *elem.Element, ancestors []*elem.Element) bool {
+	if !cs[0].compound.matches(e) {
+		return false
+	}
+	return cs.matchAncestors(1, ancestors)
+}
+
+func (cs complexSelector) matchAncestors(i int, ancestors []*elem.Element) bool {
+	if i == len(cs) {
+		return true
+	}
+	if cs[i-1].child {
+		n := len(ancestors)
+		return n > 0 && cs[i].compound.matches(ancestors[n-1]) && cs.matchAncestors(i+1, ancestors[:n-1])
+	}
+	for n := len(ancestors); n > 0; n-- {
+		if cs[i].compound.matches(ancestors[n-1]) && cs.matchAncestors(i+1, ancestors[:n-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c compoundSelector) matches(e *elem.Element) bool {
+	if c.tag != "" && c.tag != "*" && !strings.EqualFold(c.tag, e.Tag) {
+		return false
+	}
+	if c.id != "" && e.Attrs["id"] != c.id {
+		return false
+	}
+	if len(c.classes) > 0 {
+		have := strings.Fields(e.Attrs["class"])
+		for _, want := range c.classes {
+			if !contains(have, want) {
+				return false
+			}
+		}
+	}
+	for _, a := range c.attrs {
+		v, exists := e.Attrs[a.name]
+		if !exists || (elem.IsBooleanAttr(a.name) && v != "true") {
+			return false
+		}
+		if !a.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a attrSelector) matches(v string) bool {
+	switch a.op {
+	case "":
+		return true
+	case "=":
+		return v == a.value
+	case "~=":
+		return contains(strings.Fields(v), a.value)
+	case "^=":
+		return a.value != "" && strings.HasPrefix(v, a.value)
+	case "$=":
+		return a.value != "" && strings.HasSuffix(v, a.value)
+	case "*=":
+		return a.value != "" && strings.Contains(v, a.value)
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// compileSelector parses a selector list.
+func compileSelector(s string) (selector, error) {
+	var sel selector
+	for _, part := range strings.Split(s, ",") {
+		cs, err := compileComplex(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("elemtest: invalid selector %q: %w", s, err)
+		}
+		sel = append(sel, cs)
+	}
+	return sel, nil
+}
+
+func compileComplex(s string) (complexSelector, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty selector")
+	}
+	// Collect compounds left to right, each with the combinator before it,
+	// then reverse.
+	var parts []selectorPart
+	child := false
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == ' ' || s[i] == '\t' || s[i] == '\n':
+			i++
+			continue
+		case s[i] == '>':
+			if len(parts) == 0 || child {
+				return nil, fmt.Errorf("misplaced '>'")
+			}
+			child = true
+			i++
+			continue
+		}
+		compound, n, err := compileCompound(s[i:])
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, selectorPart{compound: compound, child: child})
+		child = false
+		i += n
+	}
+	if child {
+		return nil, fmt.Errorf("selector ends with '>'")
+	}
+
+	cs := make(complexSelector, len(parts))
+	for i, p := range parts {
+		cs[len(parts)-1-i] = p
+	}
+	return cs, nil
+}
+
+// compileCompound parses a compound selector at the start of s and returns
+// it with the number of bytes consumed.
+func compileCompound(s string) (compoundSelector, int, error) {
+	var c compoundSelector
+	i := 0
+	if i < len(s) && (s[i] == '*' || isNameChar(s[i])) {
+		n := nameLen(s[i:])
+		if s[i] == '*' {
+			n = 1
+		}
+		c.tag = s[i : i+n]
+		i += n
+	}
+	for i < len(s) {
+		switch s[i] {
+		case '#', '.':
+			n := nameLen(s[i+1:])
+			if n == 0 {
+				return c, 0, fmt.Errorf("missing name after %q", s[i])
+			}
+			if s[i] == '#' {
+				c.id = s[i+1 : i+1+n]
+			} else {
+				c.classes = append(c.classes, s[i+1:i+1+n])
+			}
+			i += 1 + n
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return c, 0, fmt.Errorf("unclosed '['")
+			}
+			a, err := compileAttr(s[i+1 : i+end])
+			if err != nil {
+				return c, 0, err
+			}
+			c.attrs = append(c.attrs, a)
+			i += end + 1
+		case ' ', '\t', '\n', '>':
+			return c, i, nil
+		default:
+			return c, 0, fmt.Errorf("unsupported syntax at %q", s[i:])
+		}
+	}
+	if i == 0 {
+		return c, 0, fmt.Errorf("empty compound selector")
+	}
+	return c, i, nil
+}
+
+func compileAttr(s string) (attrSelector, error) {
+	for _, op := range []string{"~=", "^=", "$=", "*=", "="} {
+		if name, value, found := strings.Cut(s, op); found {
+			value = strings.TrimSpace(value)
+			if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+			return attrSelector{name: strings.TrimSpace(name), op: op, value: value}, nil
+		}
+	}
+	name := strings.TrimSpace(s)
+	if name == "" {
+		return attrSelector{}, fmt.Errorf("empty attribute selector")
+	}
+	return attrSelector{name: name}, nil
+}
+
+func nameLen(s string) int {
+	n := 0
+	for n < len(s) && isNameChar(s[n]) {
+		n++
+	}
+	return n
+}
+
+func isNameChar(c byte) bool {
+	return c == '-' || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
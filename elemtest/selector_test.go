@@ -0,0 +1,68 @@
+package elemtest
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+var page = elem.Div(attrs.Props{attrs.ID: "app"},
+	elem.Nav(attrs.Props{attrs.Class: "menu main"},
+		elem.A(attrs.Props{attrs.Href: "/", attrs.Class: "active"}, elem.Text("Home")),
+		elem.A(attrs.Props{attrs.Href: "https://example.com/docs.pdf"}, elem.Text("Docs")),
+	),
+	elem.Fragment(
+		elem.Form(nil,
+			elem.Input(attrs.Props{attrs.Type: "email", attrs.Required: "true"}),
+			elem.Input(attrs.Props{attrs.Type: "text", attrs.Disabled: "false"}),
+		),
+	),
+	elem.P(nil, elem.Text("Hello "), elem.Strong(nil, elem.Text("world")), elem.Script(nil, elem.Raw("x()"))),
+)
+
+func TestFindAll(t *testing.T) {
+	cases := []struct {
+		selector string
+		want     int
+	}{
+		{"a", 2},
+		{"*", 10},
+		{"#app", 1},
+		{".menu", 1},
+		{".menu.main", 1},
+		{".menu.other", 0},
+		{"nav a", 2},
+		{"#app > a", 0},
+		{"#app > nav > a", 2},
+		{"div input", 2},
+		{"div > form", 1},
+		{"[required]", 1},
+		{"[disabled]", 0},
+		{"[type=email]", 1},
+		{`input[type="text"]`, 1},
+		{"[class~=main]", 1},
+		{"[href^=https]", 1},
+		{"[href$='.pdf']", 1},
+		{"[href*=example]", 1},
+		{"a.active, strong", 2},
+	}
+	for _, tc := range cases {
+		t.Run(tc.selector, func(t *testing.T) {
+			assert.Len(t, FindAll(page, tc.selector), tc.want)
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	assert.Equal(t, "/", Find(page, "nav a").Attrs[attrs.Href])
+	assert.Nil(t, Find(page, "table"))
+	assert.Panics(t, func() { Find(page, "a:hover") })
+	assert.Panics(t, func() { Find(page, "> a") })
+}
+
+func TestTextContent(t *testing.T) {
+	assert.Equal(t, "HomeDocsHello world", TextContent(page))
+	assert.Equal(t, "Hello world", TextContent(Find(page, "p")))
+}
@@ -0,0 +1,229 @@
+package forms
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Decode copies the submitted values into the struct pointed to by dst and
+// validates the result. Values that cannot be parsed into their field's type
+// are reported in the returned Errors alongside failed validation rules; the
+// result is nil when every field is valid. Decode panics if dst is not a
+// pointer to a struct or has malformed tags.
+func Decode(values url.Values, dst any) Errors {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		panic(fmt.Errorf("forms: Decode requires a non-nil pointer to a struct, got %T", dst))
+	}
+	rv, err := structValue(dst)
+	if err != nil {
+		panic(err)
+	}
+	fields, err := parseFields(rv.Type())
+	if err != nil {
+		panic(err)
+	}
+
+	errs := Errors{}
+	for _, f := range fields {
+		fv := rv.FieldByIndex(f.index)
+		if f.isSlice {
+			fv.Set(reflect.ValueOf(append([]string(nil), values[f.name]...)))
+			continue
+		}
+		value := values.Get(f.name)
+		if f.isPointer {
+			// A pointer field is nil when no value was submitted, which
+			// lets it tell an empty number from a submitted 0.
+			fv.SetZero()
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			fv.Set(reflect.New(fv.Type().Elem()))
+			fv = fv.Elem()
+		}
+		if err := setValue(fv, value); err != nil {
+			errs[f.name] = f.label + " " + err.Error()
+		}
+	}
+	for name, msg := range validate(rv, fields) {
+		if _, exists := errs[name]; !exists {
+			errs[name] = msg
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Validate checks the struct (or pointer to struct) v against the rules in
+// its validate tags and returns a message per failing field, or nil when
+// every field is valid. Decode applies the same rules after copying the
+// values. It panics under the same conditions as Render.
+func Validate(v any) Errors {
+	rv, err := structValue(v)
+	if err != nil {
+		panic(err)
+	}
+	fields, err := parseFields(rv.Type())
+	if err != nil {
+		panic(err)
+	}
+	errs := validate(rv, fields)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validate checks every field.
+func validate(rv reflect.Value, fields []field) Errors {
+	errs := Errors{}
+	for _, f := range fields {
+		if msg := validateField(f, rv.FieldByIndex(f.index)); msg != "" {
+			errs[f.name] = f.label + " " + msg
+		}
+	}
+	return errs
+}
+
+// validateField returns the reason v breaks one of the rules of f, without
+// the field label, or "" when it is valid. A nil pointer and a zero number
+// are empty, so they fail required and skip the other rules; a pointer to 0
+// is a value.
+func validateField(f field, v reflect.Value) string {
+	if f.isPointer {
+		if v.IsNil() {
+			if f.required {
+				return "is required"
+			}
+			return ""
+		}
+		v = v.Elem()
+	}
+	if f.isSlice {
+		if f.required && v.Len() == 0 {
+			return "is required"
+		}
+		return validateChoices(f, v)
+	}
+
+	switch {
+	case v.Kind() == reflect.Bool:
+		if f.required && !v.Bool() {
+			return "is required"
+		}
+		return ""
+	case v.Kind() == reflect.String:
+		s := v.String()
+		if s == "" {
+			if f.required {
+				return "is required"
+			}
+			// Length limits only apply to values that were filled in.
+			return ""
+		}
+		n := float64(utf8.RuneCountInString(s))
+		if f.min != nil && n < *f.min {
+			return fmt.Sprintf("must be at least %s characters", formatFloat(*f.min))
+		}
+		if f.max != nil && n > *f.max {
+			return fmt.Sprintf("must be at most %s characters", formatFloat(*f.max))
+		}
+		return validateChoices(f, v)
+	}
+
+	n := numericValue(v)
+	if n == 0 && !f.isPointer {
+		if f.required {
+			return "is required"
+		}
+		return ""
+	}
+	if f.min != nil && n < *f.min {
+		return "must be at least " + formatFloat(*f.min)
+	}
+	if f.max != nil && n > *f.max {
+		return "must be at most " + formatFloat(*f.max)
+	}
+	return ""
+}
+
+// validateChoices rejects values outside a field's declared options, which
+// would otherwise only be possible by tampering with the submitted form.
+func validateChoices(f field, v reflect.Value) string {
+	if len(f.options) == 0 {
+		return ""
+	}
+	allowed := make(map[string]bool, len(f.options))
+	for _, o := range f.options {
+		allowed[o.Value] = true
+	}
+	for value := range selectedValues(v) {
+		if value != "" && !allowed[value] {
+			return "has an invalid choice"
+		}
+	}
+	return ""
+}
+
+// setValue parses s into the scalar field v.
+func setValue(v reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		v.SetBool(s == "true" || s == "on" || s == "1")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a whole number")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			v.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a positive whole number")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(n)
+	}
+	return nil
+}
+
+func numericValue(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return 0
+}
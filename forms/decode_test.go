@@ -0,0 +1,126 @@
+package forms
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	values := url.Values{
+		"email":   {" a@b.c "},
+		"name":    {"Ada"},
+		"age":     {"36"},
+		"plan":    {"pro"},
+		"country": {"ca"},
+		"tags":    {"go", "htmx"},
+		"bio":     {"Hello"},
+		"terms":   {"true"},
+	}
+	var s signup
+	errs := Decode(values, &s)
+
+	assert.Nil(t, errs)
+	assert.Equal(t, signup{
+		Email:   "a@b.c",
+		Name:    "Ada",
+		Age:     36,
+		Plan:    "pro",
+		Country: "ca",
+		Tags:    []string{"go", "htmx"},
+		Bio:     "Hello",
+		Terms:   true,
+	}, s)
+}
+
+func TestDecodeReportsErrors(t *testing.T) {
+	values := url.Values{
+		"name":    {"Alexandra"},
+		"age":     {"abc"},
+		"country": {"xx"},
+	}
+	var s signup
+	errs := Decode(values, &s)
+
+	assert.Equal(t, Errors{
+		"email":   "Email is required",
+		"name":    "Full name must be at most 5 characters",
+		"age":     "Age must be a whole number",
+		"country": "Country has an invalid choice",
+		"terms":   "I accept is required",
+	}, errs)
+}
+
+func TestDecodePanicsOnNonPointer(t *testing.T) {
+	assert.Panics(t, func() { Decode(url.Values{}, signup{}) })
+}
+
+func TestValidate(t *testing.T) {
+	valid := signup{Email: "a@b.c", Age: 20, Terms: true}
+	assert.Nil(t, Validate(valid))
+
+	invalid := signup{Email: "a@b.c", Name: "A", Age: 10, Terms: true}
+	assert.Equal(t, Errors{
+		"name": "Full name must be at least 2 characters",
+		"age":  "Age must be at least 18",
+	}, Validate(&invalid))
+}
+
+func TestDecodeThenRender(t *testing.T) {
+	type form struct {
+		Email string `input:"email" validate:"required"`
+	}
+	var f form
+	errs := Decode(url.Values{}, &f)
+	out := Render(f, errs, Options{}).Render()
+	assert.Contains(t, out, `aria-invalid="true"`)
+	assert.Contains(t, out, `<p class="field-error" id="email-error">Email is required</p>`)
+}
+
+func TestDecodeRequiredNumber(t *testing.T) {
+	type order struct {
+		Quantity int     `validate:"required,max=10"`
+		Discount float64 `validate:"required"`
+	}
+
+	var o order
+	assert.Equal(t, Errors{
+		"quantity": "Quantity is required",
+		"discount": "Discount is required",
+	}, Decode(url.Values{"quantity": {"0"}, "discount": {" "}}, &o), "a plain number can't tell 0 from a missing value")
+	assert.Equal(t, Errors{"quantity": "Quantity is required"}, Validate(order{Discount: 1}))
+}
+
+func TestDecodeNumberPointers(t *testing.T) {
+	type order struct {
+		Email    string   `validate:"required"`
+		Quantity *int     `validate:"required,max=10"`
+		Discount *float64 `validate:"min=5"`
+	}
+
+	var o order
+	errs := Decode(url.Values{"quantity": {"0"}, "discount": {""}}, &o)
+	assert.Equal(t, Errors{"email": "Email is required"}, errs, "a submitted 0 is a value and an empty optional number skips min")
+	assert.Equal(t, 0, *o.Quantity)
+	assert.Nil(t, o.Discount)
+	assert.Equal(t, errs, Validate(o), "Validate should apply the same rules as Decode")
+	assert.Contains(t, Render(o, errs, Options{}).Render(),
+		`<input id="quantity" max="10" name="quantity" required type="number" value="0">`)
+
+	assert.Equal(t, Errors{
+		"email":    "Email is required",
+		"quantity": "Quantity is required",
+		"discount": "Discount must be at least 5",
+	}, Decode(url.Values{"quantity": {" "}, "discount": {"1"}}, &o))
+	assert.Nil(t, o.Quantity)
+}
+
+func TestValidateSkipsEmptyOptionalNumbers(t *testing.T) {
+	type form struct {
+		Age int `validate:"min=18"`
+	}
+	assert.Nil(t, Validate(form{}))
+	assert.Nil(t, Decode(url.Values{}, &form{}))
+	assert.Equal(t, Errors{"age": "Age must be at least 18"}, Validate(form{Age: 10}))
+}
@@ -0,0 +1,211 @@
+package forms
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Input types understood by the `input` struct tag in addition to the
+// standard HTML input types.
+const (
+	TypeTextarea = "textarea"
+	TypeSelect   = "select"
+	TypeRadio    = "radio"
+	TypeCheckbox = "checkbox"
+)
+
+// Option is a single choice of a select or radio field.
+type Option struct {
+	Value string
+	Label string
+}
+
+// field describes one struct field as parsed from its tags.
+type field struct {
+	index       []int
+	kind        reflect.Kind
+	isSlice     bool
+	isPointer   bool
+	name        string
+	label       string
+	inputType   string
+	placeholder string
+	help        string
+	required    bool
+	min         *float64
+	max         *float64
+	options     []Option
+}
+
+// parseFields returns the form fields of the struct type t in declaration
+// order. Unexported fields and fields tagged `form:"-"` are skipped.
+func parseFields(t reflect.Type) ([]field, error) {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Tag.Get("form")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = snakeCase(sf.Name)
+		}
+
+		f := field{
+			index:       sf.Index,
+			kind:        sf.Type.Kind(),
+			name:        name,
+			label:       sf.Tag.Get("label"),
+			inputType:   sf.Tag.Get("input"),
+			placeholder: sf.Tag.Get("placeholder"),
+			help:        sf.Tag.Get("help"),
+		}
+		if f.kind == reflect.Pointer {
+			f.isPointer = true
+			f.kind = sf.Type.Elem().Kind()
+		} else if f.kind == reflect.Slice {
+			if sf.Type.Elem().Kind() != reflect.String {
+				return nil, fmt.Errorf("forms: field %s: only []string slices are supported", sf.Name)
+			}
+			f.isSlice = true
+			f.kind = reflect.String
+		}
+		if !supportedKind(f.kind) {
+			return nil, fmt.Errorf("forms: field %s: unsupported type %s", sf.Name, sf.Type)
+		}
+		if f.label == "" {
+			f.label = humanize(sf.Name)
+		}
+		if opts := sf.Tag.Get("options"); opts != "" {
+			f.options = parseOptions(opts)
+		}
+		if err := f.parseValidate(sf.Tag.Get("validate")); err != nil {
+			return nil, fmt.Errorf("forms: field %s: %w", sf.Name, err)
+		}
+		if f.inputType == "" {
+			f.inputType = defaultInputType(f)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// parseValidate reads the comma separated rules of the `validate` tag,
+// e.g. `validate:"required,min=3,max=64"`.
+func (f *field) parseValidate(tag string) error {
+	if tag == "" {
+		return nil
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		key, val, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			f.required = true
+		case "min", "max":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s value %q", key, val)
+			}
+			if key == "min" {
+				f.min = &n
+			} else {
+				f.max = &n
+			}
+		case "":
+		default:
+			return fmt.Errorf("unknown validation rule %q", key)
+		}
+	}
+	return nil
+}
+
+// parseOptions reads the `options` tag, a list of value:label pairs separated
+// by "|". A pair without a label uses the value as its label.
+func parseOptions(tag string) []Option {
+	var options []Option
+	for _, pair := range strings.Split(tag, "|") {
+		value, label, found := strings.Cut(pair, ":")
+		if !found {
+			label = value
+		}
+		options = append(options, Option{Value: value, Label: label})
+	}
+	return options
+}
+
+func supportedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isNumeric(k reflect.Kind) bool {
+	return k != reflect.String && k != reflect.Bool
+}
+
+func defaultInputType(f field) string {
+	switch {
+	case len(f.options) > 0:
+		return TypeSelect
+	case f.kind == reflect.Bool:
+		return TypeCheckbox
+	case isNumeric(f.kind):
+		return "number"
+	}
+	return "text"
+}
+
+// snakeCase converts a Go identifier such as "FirstName" to "first_name".
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word on a lower-to-upper transition, or at the last
+			// capital of an acronym ("HTTPServer" -> "http_server").
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// humanize converts a Go identifier such as "FirstName" to "First name".
+func humanize(s string) string {
+	words := strings.Split(snakeCase(s), "_")
+	if len(words) > 0 && words[0] != "" {
+		r := []rune(words[0])
+		r[0] = unicode.ToUpper(r[0])
+		words[0] = string(r)
+	}
+	return strings.Join(words, " ")
+}
+
+// structValue dereferences v and reports whether it is a struct.
+func structValue(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("forms: nil %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("forms: expected a struct, got %s", rv.Type())
+	}
+	return rv, nil
+}
@@ -0,0 +1,282 @@
+// Package forms renders HTML forms from Go structs and decodes submitted
+// values back into them, so a single struct definition drives both sides.
+//
+// Fields are described with struct tags:
+//
+//	type Signup struct {
+//	    Email string `form:"email" input:"email" placeholder:"you@example.com" validate:"required"`
+//	    Name  string `label:"Full name" validate:"required,min=2,max=64"`
+//	    Plan  string `input:"radio" options:"free:Free|pro:Pro"`
+//	    Terms bool   `label:"I accept the terms" validate:"required"`
+//	}
+//
+// Supported tags are form (the field name, "-" to skip), label, input (the
+// input type, or one of textarea, select and radio), placeholder, help,
+// options (value:label pairs separated by "|") and validate (required, min
+// and max; min and max bound the length of strings and the value of numbers).
+//
+// A number of 0 counts as empty, so it fails required. Use a pointer field,
+// such as *int, to accept 0: a nil pointer is empty and a pointer to 0 isn't.
+package forms
+
+import (
+	"html"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+)
+
+// Errors maps field names to validation messages.
+type Errors map[string]string
+
+// Options configures how a form is rendered.
+type Options struct {
+	// Action and Method set the form's action and method attributes. Method
+	// defaults to "post".
+	Action string
+	Method string
+	// Submit is the label of the submit button. No button is rendered when
+	// it is empty.
+	Submit string
+	// IDPrefix is prepended to every generated id, which keeps ids unique
+	// when a page contains more than one form.
+	IDPrefix string
+	// FieldClass and ErrorClass are the classes of each field's wrapper and
+	// of its error message. They default to "field" and "field-error".
+	FieldClass string
+	ErrorClass string
+	// Attrs are merged onto the <form> element.
+	Attrs attrs.Props
+}
+
+// Render builds a <form> for the struct (or pointer to struct) v, populated
+// with its current values. Messages in errs are rendered next to the fields
+// they belong to. Render panics if v is not a struct or has malformed tags,
+// as that is a programming error rather than a user one.
+func Render(v any, errs Errors, opts Options) *elem.Element {
+	method := opts.Method
+	if method == "" {
+		method = "post"
+	}
+	formAttrs := attrs.Merge(attrs.Props{attrs.Method: method}, opts.Attrs)
+	if opts.Action != "" {
+		formAttrs[attrs.Action] = html.EscapeString(opts.Action)
+	}
+
+	children := Fields(v, errs, opts)
+	if opts.Submit != "" {
+		children = append(children, elem.Button(attrs.Props{attrs.Type: "submit"}, elem.Text(opts.Submit)))
+	}
+	return elem.Form(formAttrs, children...)
+}
+
+// Fields renders only the fields of v, for embedding them in a hand-built
+// form. It panics under the same conditions as Render.
+func Fields(v any, errs Errors, opts Options) []elem.Node {
+	rv, err := structValue(v)
+	if err != nil {
+		panic(err)
+	}
+	fields, err := parseFields(rv.Type())
+	if err != nil {
+		panic(err)
+	}
+	if opts.FieldClass == "" {
+		opts.FieldClass = "field"
+	}
+	if opts.ErrorClass == "" {
+		opts.ErrorClass = "field-error"
+	}
+
+	nodes := make([]elem.Node, 0, len(fields))
+	for _, f := range fields {
+		nodes = append(nodes, renderField(f, rv.FieldByIndex(f.index), errs[f.name], opts))
+	}
+	return nodes
+}
+
+func renderField(f field, v reflect.Value, errMsg string, opts Options) elem.Node {
+	id := opts.IDPrefix + f.name
+	errorID := id + "-error"
+	helpID := id + "-help"
+
+	// Attributes shared by every control rendered for this field.
+	common := attrs.Props{attrs.Name: f.name}
+	describedBy := ""
+	if f.help != "" {
+		describedBy = helpID
+	}
+	if errMsg != "" {
+		common[attrs.AriaInvalid] = "true"
+		describedBy = strings.TrimSpace(describedBy + " " + errorID)
+	}
+	if describedBy != "" {
+		common[attrs.AriaDescribedby] = describedBy
+	}
+
+	var control []elem.Node
+	switch {
+	case f.inputType == TypeRadio || (f.inputType == TypeCheckbox && f.isSlice):
+		control = []elem.Node{renderChoiceGroup(f, v, id, common)}
+	case f.inputType == TypeCheckbox:
+		input := attrs.Merge(common, constraintAttrs(f), attrs.Props{
+			attrs.Type:  TypeCheckbox,
+			attrs.ID:    id,
+			attrs.Value: "true",
+		})
+		if formatValue(v) == "true" {
+			input[attrs.Checked] = "true"
+		}
+		control = []elem.Node{
+			elem.Input(input),
+			elem.Label(attrs.Props{attrs.For: id}, elem.Text(f.label)),
+		}
+	default:
+		control = []elem.Node{
+			elem.Label(attrs.Props{attrs.For: id}, elem.Text(f.label)),
+			renderControl(f, v, id, common),
+		}
+	}
+
+	if f.help != "" {
+		control = append(control, elem.Small(attrs.Props{attrs.ID: helpID}, elem.Text(f.help)))
+	}
+	if errMsg != "" {
+		control = append(control, elem.P(attrs.Props{attrs.ID: errorID, attrs.Class: opts.ErrorClass}, elem.Text(errMsg)))
+	}
+	return elem.Div(attrs.Props{attrs.Class: opts.FieldClass}, control...)
+}
+
+// renderControl renders the single control of a text-like, select or
+// textarea field.
+func renderControl(f field, v reflect.Value, id string, common attrs.Props) elem.Node {
+	props := attrs.Merge(common, constraintAttrs(f), attrs.Props{attrs.ID: id})
+	if f.placeholder != "" {
+		props[attrs.Placeholder] = html.EscapeString(f.placeholder)
+	}
+
+	switch f.inputType {
+	case TypeTextarea:
+		return elem.Textarea(props, elem.Text(formatValue(v)))
+	case TypeSelect:
+		selected := selectedValues(v)
+		if f.isSlice {
+			props[attrs.Multiple] = "true"
+		}
+		options := make([]elem.Node, 0, len(f.options))
+		for _, o := range f.options {
+			optAttrs := attrs.Props{attrs.Value: html.EscapeString(o.Value)}
+			if selected[o.Value] {
+				optAttrs[attrs.Selected] = "true"
+			}
+			options = append(options, elem.Option(optAttrs, elem.Text(o.Label)))
+		}
+		return elem.Select(props, options...)
+	}
+
+	props[attrs.Type] = f.inputType
+	if value := formatValue(v); value != "" {
+		props[attrs.Value] = html.EscapeString(value)
+	}
+	return elem.Input(props)
+}
+
+// renderChoiceGroup renders radio buttons, or checkboxes for a []string
+// field, wrapped in a <fieldset> whose <legend> is the field label.
+func renderChoiceGroup(f field, v reflect.Value, id string, common attrs.Props) elem.Node {
+	selected := selectedValues(v)
+	children := []elem.Node{elem.Legend(nil, elem.Text(f.label))}
+	for i, o := range f.options {
+		optionID := id + "-" + strconv.Itoa(i)
+		input := attrs.Merge(common, attrs.Props{
+			attrs.Type:  f.inputType,
+			attrs.ID:    optionID,
+			attrs.Value: html.EscapeString(o.Value),
+		})
+		// A required checkbox group would force every box to be checked,
+		// so only radios carry the attribute.
+		if f.required && f.inputType == TypeRadio {
+			input[attrs.Required] = "true"
+		}
+		if selected[o.Value] {
+			input[attrs.Checked] = "true"
+		}
+		children = append(children,
+			elem.Input(input),
+			elem.Label(attrs.Props{attrs.For: optionID}, elem.Text(o.Label)),
+		)
+	}
+	return elem.Fieldset(nil, children...)
+}
+
+// constraintAttrs maps the validation rules of f onto the matching HTML
+// constraint attributes so browsers validate before submitting.
+func constraintAttrs(f field) attrs.Props {
+	props := attrs.Props{}
+	if f.required {
+		props[attrs.Required] = "true"
+	}
+	minAttr, maxAttr := attrs.Min, attrs.Max
+	if !isNumeric(f.kind) {
+		minAttr, maxAttr = attrs.Minlength, attrs.Maxlength
+	}
+	if f.min != nil {
+		props[minAttr] = formatFloat(*f.min)
+	}
+	if f.max != nil {
+		props[maxAttr] = formatFloat(*f.max)
+	}
+	return props
+}
+
+func selectedValues(v reflect.Value) map[string]bool {
+	selected := map[string]bool{}
+	if v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			selected[v.Index(i).String()] = true
+		}
+	} else {
+		selected[formatValue(v)] = true
+	}
+	return selected
+}
+
+// formatValue renders a scalar field value the way it should appear in an
+// input. Zero numbers and nil pointers render as empty so that untouched
+// forms show their placeholders, while a pointer to 0 renders as 0.
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		return formatScalar(v.Elem())
+	}
+	if v.Kind() != reflect.String && v.Kind() != reflect.Bool && v.IsZero() {
+		return ""
+	}
+	return formatScalar(v)
+}
+
+// formatScalar renders a scalar value.
+func formatScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return formatFloat(v.Float())
+	}
+	return ""
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,99 @@
+package forms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type signup struct {
+	Email    string   `form:"email" input:"email" placeholder:"you@example.com" validate:"required"`
+	Name     string   `label:"Full name" validate:"min=2,max=5"`
+	Age      int      `validate:"min=18"`
+	Plan     string   `input:"radio" options:"free:Free|pro:Pro"`
+	Country  string   `options:"us:United States|ca:Canada"`
+	Tags     []string `input:"checkbox" options:"go|htmx"`
+	Bio      string   `input:"textarea"`
+	Terms    bool     `label:"I accept" validate:"required"`
+	internal string
+	Skipped  string `form:"-"`
+}
+
+func TestRenderTextInput(t *testing.T) {
+	type form struct {
+		Email string `form:"email" input:"email" placeholder:"you@example.com" validate:"required"`
+	}
+	expected := `<form method="post"><div class="field"><label for="email">Email</label><input id="email" name="email" placeholder="you@example.com" required type="email" value="a@b.c"></div></form>`
+	assert.Equal(t, expected, Render(form{Email: "a@b.c"}, nil, Options{}).Render())
+}
+
+func TestRenderWithErrors(t *testing.T) {
+	type form struct {
+		Name string `help:"Your legal name"`
+	}
+	expected := `<form action="/signup" method="post"><div class="field"><label for="s-name">Name</label><input aria-describedby="s-name-help s-name-error" aria-invalid="true" id="s-name" name="name" type="text"><small id="s-name-help">Your legal name</small><p class="field-error" id="s-name-error">Name is required</p></div><button type="submit">Sign up</button></form>`
+	el := Render(form{}, Errors{"name": "Name is required"}, Options{Action: "/signup", Submit: "Sign up", IDPrefix: "s-"})
+	assert.Equal(t, expected, el.Render())
+}
+
+func TestRenderSelect(t *testing.T) {
+	type form struct {
+		Country string `options:"us:United States|ca:Canada"`
+	}
+	expected := `<div class="field"><label for="country">Country</label><select id="country" name="country"><option value="us">United States</option><option selected value="ca">Canada</option></select></div>`
+	nodes := Fields(form{Country: "ca"}, nil, Options{})
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, expected, nodes[0].Render())
+}
+
+func TestRenderRadioGroup(t *testing.T) {
+	type form struct {
+		Plan string `input:"radio" options:"free:Free|pro:Pro" validate:"required"`
+	}
+	expected := `<div class="field"><fieldset><legend>Plan</legend><input id="plan-0" name="plan" required type="radio" value="free"><label for="plan-0">Free</label><input checked id="plan-1" name="plan" required type="radio" value="pro"><label for="plan-1">Pro</label></fieldset></div>`
+	assert.Equal(t, expected, Fields(form{Plan: "pro"}, nil, Options{})[0].Render())
+}
+
+func TestRenderCheckbox(t *testing.T) {
+	type form struct {
+		Terms bool `label:"I accept"`
+	}
+	expected := `<div class="field"><input checked id="terms" name="terms" type="checkbox" value="true"><label for="terms">I accept</label></div>`
+	assert.Equal(t, expected, Fields(form{Terms: true}, nil, Options{})[0].Render())
+}
+
+func TestRenderTextareaAndNumbers(t *testing.T) {
+	type form struct {
+		Bio   string `input:"textarea" validate:"max=200"`
+		Age   int    `validate:"min=18,max=130"`
+		Price float64
+	}
+	nodes := Fields(form{Bio: "<b>hi</b>", Age: 30, Price: 9.5}, nil, Options{})
+	assert.Equal(t, `<div class="field"><label for="bio">Bio</label><textarea id="bio" maxlength="200" name="bio">&lt;b&gt;hi&lt;/b&gt;</textarea></div>`, nodes[0].Render())
+	assert.Equal(t, `<div class="field"><label for="age">Age</label><input id="age" max="130" min="18" name="age" type="number" value="30"></div>`, nodes[1].Render())
+	assert.Equal(t, `<div class="field"><label for="price">Price</label><input id="price" name="price" type="number" value="9.5"></div>`, nodes[2].Render())
+}
+
+func TestRenderEscapesValues(t *testing.T) {
+	type form struct {
+		Name string
+	}
+	out := Fields(form{Name: `"><script>`}, nil, Options{})[0].Render()
+	assert.Contains(t, out, `value="&#34;&gt;&lt;script&gt;"`)
+}
+
+func TestRenderSkipsUnexportedAndIgnoredFields(t *testing.T) {
+	nodes := Fields(&signup{}, nil, Options{})
+	assert.Len(t, nodes, 8)
+}
+
+func TestRenderPanicsOnNonStruct(t *testing.T) {
+	assert.Panics(t, func() { Render("nope", nil, Options{}) })
+}
+
+func TestSnakeCaseAndHumanize(t *testing.T) {
+	assert.Equal(t, "first_name", snakeCase("FirstName"))
+	assert.Equal(t, "http_server", snakeCase("HTTPServer"))
+	assert.Equal(t, "id", snakeCase("ID"))
+	assert.Equal(t, "First name", humanize("FirstName"))
+}
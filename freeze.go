@@ -0,0 +1,176 @@
+package elem
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// FrozenNode is a subtree rendered ahead of time. Rendering it writes the
+// stored HTML instead of walking and escaping the tree again, which pays off
+// for parts of a page that are the same on every request, such as
+// navigation, footers or icon sprites.
+//
+// A frozen node is safe for concurrent use. It is opaque to Walk, Validate
+// and render hooks, so check the subtree before freezing it.
+type FrozenNode struct {
+	node Node
+	// classes holds the classes of the subtree, for TreeShakeCSS.
+	classes classSet
+	// variants holds the output for each combination of the options that
+	// change how a subtree renders, computed the first time it's needed.
+	variants [8]frozenVariant
+}
+
+type frozenVariant struct {
+	once sync.Once
+	html string
+}
+
+// Freeze renders node and returns a node that writes the result from then
+// on. The node is copied first, so later changes to it don't affect the
+// frozen node.
+func Freeze(node Node) *FrozenNode {
+	f := &FrozenNode{node: CloneNode(node), classes: classSet{names: make(map[string]struct{})}}
+	f.render(RenderOptions{})
+	return f
+}
+
+// render returns the output for opts, rendering it on first use.
+func (f *FrozenNode) render(opts RenderOptions) string {
+	i := 0
+	if opts.DisableHtmlPreamble {
+		i |= 1
+	}
+	if opts.Minify {
+		i |= 2
+	}
+	if opts.keepWhitespace {
+		i |= 4
+	}
+	v := &f.variants[i]
+	v.once.Do(func() {
+		renderOpts := RenderOptions{
+			DisableHtmlPreamble: opts.DisableHtmlPreamble,
+			Minify:              opts.Minify,
+			keepWhitespace:      opts.keepWhitespace,
+		}
+		// The default variant is rendered by Freeze, before the node is
+		// shared, and all variants use the same classes.
+		if i == 0 {
+			renderOpts.usedClasses = &f.classes
+		}
+		var builder strings.Builder
+		f.node.RenderTo(&builder, renderOpts)
+		v.html = builder.String()
+	})
+	return v.html
+}
+
+func (f *FrozenNode) RenderTo(builder *strings.Builder, opts RenderOptions) {
+	if opts.usedClasses != nil {
+		for name := range f.classes.names {
+			opts.usedClasses.names[name] = struct{}{}
+		}
+	}
+	builder.WriteString(f.render(opts))
+}
+
+func (f *FrozenNode) Render() string {
+	return f.render(RenderOptions{})
+}
+
+func (f *FrozenNode) RenderWithOptions(opts RenderOptions) string {
+	return f.render(opts)
+}
+
+// Memo caches frozen nodes by key, building each with the function passed to
+// Get the first time its key is requested. Entries expire after the TTL
+// given to NewMemo, if any. A Memo is safe for concurrent use, and a node is
+// built only once even if its key is requested concurrently.
+type Memo[K comparable] struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[K]*memoEntry
+	swept   time.Time
+	now     func() time.Time
+}
+
+type memoEntry struct {
+	once    sync.Once
+	node    *FrozenNode
+	expires time.Time
+}
+
+// NewMemo creates a Memo whose entries are rebuilt once they are older than
+// ttl. A ttl of zero keeps entries until they are deleted.
+func NewMemo[K comparable](ttl time.Duration) *Memo[K] {
+	return &Memo[K]{
+		ttl:     ttl,
+		entries: make(map[K]*memoEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the frozen node for key, calling build and freezing its result
+// if the key isn't cached or its entry has expired. If build panics, the
+// entry is removed before the panic propagates, so the next Get builds it
+// again, and concurrent calls waiting for the same key retry.
+func (m *Memo[K]) Get(key K, build func() Node) *FrozenNode {
+	for {
+		m.mu.Lock()
+		now := m.now()
+		entry, ok := m.entries[key]
+		if !ok || (m.ttl > 0 && !now.Before(entry.expires)) {
+			entry = &memoEntry{expires: now.Add(m.ttl)}
+			m.entries[key] = entry
+			m.removeExpired(now)
+		}
+		m.mu.Unlock()
+
+		entry.once.Do(func() {
+			defer func() {
+				if entry.node == nil {
+					m.mu.Lock()
+					if m.entries[key] == entry {
+						delete(m.entries, key)
+					}
+					m.mu.Unlock()
+				}
+			}()
+			entry.node = Freeze(build())
+		})
+		if entry.node != nil {
+			return entry.node
+		}
+	}
+}
+
+// removeExpired drops the expired entries, so that keys which aren't
+// requested again don't stay in memory. It looks at the entries at most once
+// per TTL.
+func (m *Memo[K]) removeExpired(now time.Time) {
+	if m.ttl <= 0 || now.Sub(m.swept) < m.ttl {
+		return
+	}
+	m.swept = now
+	for key, entry := range m.entries {
+		if !now.Before(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// Delete removes the entry for key, so the next Get builds it again.
+func (m *Memo[K]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+}
+
+// Clear removes all entries.
+func (m *Memo[K]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	clear(m.entries)
+}
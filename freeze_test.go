@@ -0,0 +1,102 @@
+package elem
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeze(t *testing.T) {
+	nav := Ul(attrs.Props{attrs.Class: "nav"},
+		Li(nil, A(attrs.Props{attrs.Href: "/"}, Text("Home & more"))),
+		Li(nil, A(attrs.Props{attrs.Href: "/about"}, Text("About"))),
+	)
+	want := nav.Render()
+
+	frozen := Freeze(nav)
+	nav.Children = nil
+
+	assert.Equal(t, want, frozen.Render())
+	assert.Equal(t, "<body>"+want+"</body>", Body(nil, frozen).Render())
+	assert.Equal(t, `<ul class=nav><li><a href=/>Home &amp; more</a><li><a href=/about>About</a></ul>`,
+		frozen.RenderWithOptions(RenderOptions{Minify: true}))
+}
+
+func TestFreezeKeepsWhitespaceInPre(t *testing.T) {
+	frozen := Freeze(Span(nil, Text("a  b")))
+	out := Div(nil, Pre(nil, frozen), frozen).RenderWithOptions(RenderOptions{Minify: true})
+	assert.Equal(t, "<div><pre><span>a  b</span></pre><span>a b</span></div>", out)
+}
+
+func TestMemo(t *testing.T) {
+	memo := NewMemo[string](0)
+	var builds int32
+	build := func() Node {
+		atomic.AddInt32(&builds, 1)
+		return Footer(nil, Text("footer"))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "<footer>footer</footer>", memo.Get("footer", build).Render())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), builds, "The node should be built once")
+
+	memo.Get("other", build)
+	assert.Equal(t, int32(2), builds, "Each key should be built")
+
+	memo.Delete("footer")
+	memo.Get("footer", build)
+	assert.Equal(t, int32(3), builds, "Deleted keys should be rebuilt")
+
+	memo.Clear()
+	memo.Get("footer", build)
+	memo.Get("other", build)
+	assert.Equal(t, int32(5), builds, "Cleared keys should be rebuilt")
+}
+
+func TestMemoBuildPanics(t *testing.T) {
+	memo := NewMemo[string](0)
+	assert.PanicsWithValue(t, "boom", func() {
+		memo.Get("footer", func() Node { panic("boom") })
+	})
+	assert.Empty(t, memo.entries, "A failed build should not be cached")
+
+	node := memo.Get("footer", func() Node { return Footer(nil, Text("footer")) })
+	assert.Equal(t, "<footer>footer</footer>", node.Render(), "The key should be built again after a panic")
+}
+
+func TestMemoTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	memo := NewMemo[int](time.Minute)
+	memo.now = func() time.Time { return now }
+
+	builds := 0
+	build := func() Node {
+		builds++
+		return Text("x")
+	}
+
+	memo.Get(1, build)
+	now = now.Add(30 * time.Second)
+	memo.Get(1, build)
+	assert.Equal(t, 1, builds, "Entries should be cached within the TTL")
+
+	now = now.Add(30 * time.Second)
+	memo.Get(1, build)
+	assert.Equal(t, 2, builds, "Expired entries should be rebuilt")
+
+	memo.Get(2, build)
+	now = now.Add(2 * time.Minute)
+	memo.Get(3, build)
+	assert.Len(t, memo.entries, 1, "Expired entries should be removed")
+}
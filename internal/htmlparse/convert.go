@@ -0,0 +1,58 @@
+package htmlparse
+
+import (
+	"html"
+	"strings"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+)
+
+// ToElem converts parsed nodes into elem nodes that render back to
+// equivalent markup. Attribute values are re-escaped, since elem renders
+// attribute values verbatim; boolean attributes are set to "true"; the
+// contents of script and style elements become raw nodes.
+func ToElem(nodes []*Node) []elem.Node {
+	out := make([]elem.Node, 0, len(nodes))
+	for _, n := range nodes {
+		out = append(out, toElem(n))
+	}
+	return out
+}
+
+func toElem(n *Node) elem.Node {
+	switch n.Type {
+	case TextNode:
+		return elem.Text(n.Data)
+	case CommentNode:
+		// elem pads comments with a space on each side.
+		return elem.Comment(strings.TrimSuffix(strings.TrimPrefix(n.Data, " "), " "))
+	}
+
+	var props attrs.Props
+	if len(n.Attrs) > 0 {
+		props = make(attrs.Props, len(n.Attrs))
+		for _, a := range n.Attrs {
+			props[a.Name] = AttrValue(a)
+		}
+	}
+
+	children := make([]elem.Node, 0, len(n.Children))
+	for _, child := range n.Children {
+		if child.Type == TextNode && (n.Tag == "script" || n.Tag == "style") {
+			children = append(children, elem.Raw(child.Data))
+			continue
+		}
+		children = append(children, toElem(child))
+	}
+	return elem.NewElement(n.Tag, props, children...)
+}
+
+// AttrValue returns the value of a for an elem attribute map: "true" for
+// boolean attributes and the escaped value otherwise.
+func AttrValue(a Attr) string {
+	if elem.IsBooleanAttr(a.Name) {
+		return "true"
+	}
+	return html.EscapeString(a.Value)
+}
@@ -0,0 +1,389 @@
+// Package htmlparse is a small, forgiving HTML fragment parser. It builds a
+// simple node tree that the rest of the module converts into elem nodes,
+// either verbatim (for comparing markup in tests) or filtered (for
+// sanitizing untrusted markup).
+//
+// It is not a full implementation of the HTML parsing algorithm: it handles
+// tags, attributes, character references, comments, raw text elements and the
+// common cases of implied end tags, which covers markup written by people and
+// produced by renderers.
+package htmlparse
+
+import (
+	"html"
+	"strings"
+)
+
+// NodeType identifies the kind of a Node.
+type NodeType int
+
+const (
+	ElementNode NodeType = iota
+	TextNode
+	CommentNode
+)
+
+// Attr is an attribute with its unescaped value. HasValue is false for
+// attributes written without a value, such as `disabled`.
+type Attr struct {
+	Name     string
+	Value    string
+	HasValue bool
+}
+
+// Node is a parsed element, text or comment. Tag names and attribute names
+// are lowercased; text and attribute values have character references
+// decoded.
+type Node struct {
+	Type     NodeType
+	Tag      string
+	Attrs    []Attr
+	Children []*Node
+	// Data is the text of a text or comment node.
+	Data string
+}
+
+// Attr returns the value of the named attribute and whether it is present.
+func (n *Node) Attr(name string) (string, bool) {
+	for _, a := range n.Attrs {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+var voidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "command": true, "embed": true,
+	"hr": true, "img": true, "input": true, "keygen": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// rawTextTags hold text up to their matching end tag without parsing any
+// markup inside. The contents of textarea and title still have character
+// references decoded.
+var rawTextTags = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+	"xmp": true, "iframe": true, "noembed": true, "noframes": true,
+}
+
+// closesP lists the start tags that implicitly close an open <p>.
+var closesP = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "details": true,
+	"dialog": true, "div": true, "dl": true, "fieldset": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hgroup": true, "hr": true,
+	"main": true, "menu": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "ul": true,
+}
+
+// impliedEndTags maps a start tag to the open tags it closes when they are
+// the current element, e.g. a new <li> closes the previous <li>.
+var impliedEndTags = map[string][]string{
+	"li":       {"li"},
+	"dt":       {"dt", "dd"},
+	"dd":       {"dt", "dd"},
+	"tr":       {"tr", "td", "th"},
+	"td":       {"td", "th"},
+	"th":       {"td", "th"},
+	"thead":    {"tbody", "tfoot", "tr", "td", "th"},
+	"tbody":    {"thead", "tfoot", "tr", "td", "th"},
+	"tfoot":    {"thead", "tbody", "tr", "td", "th"},
+	"option":   {"option"},
+	"optgroup": {"option", "optgroup"},
+	"rt":       {"rt", "rp"},
+	"rp":       {"rt", "rp"},
+}
+
+// Parse parses an HTML fragment into a list of top-level nodes. It never
+// fails: malformed markup is recovered from the way browsers typically do,
+// and stray end tags are ignored. A doctype is skipped.
+func Parse(s string) []*Node {
+	p := parser{src: s, root: &Node{}}
+	p.stack = []*Node{p.root}
+	p.parse()
+	return p.root.Children
+}
+
+type parser struct {
+	src   string
+	pos   int
+	root  *Node
+	stack []*Node
+}
+
+func (p *parser) current() *Node {
+	return p.stack[len(p.stack)-1]
+}
+
+func (p *parser) appendChild(n *Node) {
+	parent := p.current()
+	// Merge adjacent text, e.g. around a skipped stray end tag.
+	if n.Type == TextNode && len(parent.Children) > 0 {
+		if last := parent.Children[len(parent.Children)-1]; last.Type == TextNode {
+			last.Data += n.Data
+			return
+		}
+	}
+	parent.Children = append(parent.Children, n)
+}
+
+func (p *parser) parse() {
+	for p.pos < len(p.src) {
+		lt := strings.IndexByte(p.src[p.pos:], '<')
+		if lt < 0 {
+			p.text(p.src[p.pos:])
+			return
+		}
+		if lt > 0 {
+			p.text(p.src[p.pos : p.pos+lt])
+			p.pos += lt
+		}
+		rest := p.src[p.pos:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			p.comment()
+		case strings.HasPrefix(rest, "</") && len(rest) > 2 && isASCIILetter(rest[2]):
+			p.endTag()
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			// Doctype, CDATA outside foreign content or a processing
+			// instruction: skip up to the next '>'.
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				p.pos = len(p.src)
+			} else {
+				p.pos += end + 1
+			}
+		case len(rest) > 1 && isASCIILetter(rest[1]):
+			p.startTag()
+		default:
+			// A '<' that doesn't start markup is literal text.
+			p.text("<")
+			p.pos++
+		}
+	}
+}
+
+func (p *parser) text(raw string) {
+	p.appendChild(&Node{Type: TextNode, Data: html.UnescapeString(raw)})
+}
+
+func (p *parser) comment() {
+	rest := p.src[p.pos+4:]
+	end := strings.Index(rest, "-->")
+	if end < 0 {
+		p.appendChild(&Node{Type: CommentNode, Data: rest})
+		p.pos = len(p.src)
+		return
+	}
+	p.appendChild(&Node{Type: CommentNode, Data: rest[:end]})
+	p.pos += 4 + end + 3
+}
+
+func (p *parser) endTag() {
+	p.pos += 2
+	name := strings.ToLower(p.readName())
+	// Skip anything else up to the closing '>'.
+	if end := strings.IndexByte(p.src[p.pos:], '>'); end >= 0 {
+		p.pos += end + 1
+	} else {
+		p.pos = len(p.src)
+	}
+	p.closeTag(name)
+}
+
+// closeTag pops the stack up to and including the innermost open element
+// named name. An end tag with no matching open element is ignored.
+func (p *parser) closeTag(name string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		if p.stack[i].Tag == name {
+			p.stack = p.stack[:i]
+			return
+		}
+	}
+}
+
+func (p *parser) startTag() {
+	p.pos++
+	n := &Node{Type: ElementNode, Tag: strings.ToLower(p.readName())}
+	selfClosing := p.readAttrs(n)
+
+	p.closeImplied(n.Tag)
+	p.appendChild(n)
+
+	if voidTags[n.Tag] || selfClosing {
+		return
+	}
+	if rawTextTags[n.Tag] {
+		p.rawText(n)
+		return
+	}
+	p.stack = append(p.stack, n)
+}
+
+// closeImplied closes the open elements that a start tag implicitly ends.
+func (p *parser) closeImplied(tag string) {
+	if closesP[tag] {
+		p.closeInScope("p")
+	}
+	for {
+		closed := false
+		for _, open := range impliedEndTags[tag] {
+			if p.current().Tag == open {
+				p.stack = p.stack[:len(p.stack)-1]
+				closed = true
+				break
+			}
+		}
+		if !closed {
+			return
+		}
+	}
+}
+
+// closeInScope closes the innermost open element named tag unless a
+// sectioning boundary such as a table cell or list item is in between.
+func (p *parser) closeInScope(tag string) {
+	for i := len(p.stack) - 1; i > 0; i-- {
+		switch p.stack[i].Tag {
+		case tag:
+			p.stack = p.stack[:i]
+			return
+		case "td", "th", "li", "dd", "dt", "button", "table", "template", "caption":
+			return
+		}
+	}
+}
+
+// rawText consumes the content of a raw text element up to its end tag.
+func (p *parser) rawText(n *Node) {
+	rest := p.src[p.pos:]
+	end := indexEndTag(rest, n.Tag)
+	content := rest
+	if end < 0 {
+		p.pos = len(p.src)
+	} else {
+		content = rest[:end]
+		p.pos += end
+		if gt := strings.IndexByte(p.src[p.pos:], '>'); gt >= 0 {
+			p.pos += gt + 1
+		} else {
+			p.pos = len(p.src)
+		}
+	}
+	if content == "" {
+		return
+	}
+	if n.Tag == "textarea" || n.Tag == "title" {
+		content = html.UnescapeString(content)
+	}
+	n.Children = append(n.Children, &Node{Type: TextNode, Data: content})
+}
+
+// indexEndTag returns the index of the case-insensitive end tag </tag in s.
+func indexEndTag(s, tag string) int {
+	lower := strings.ToLower(s)
+	for from := 0; ; {
+		i := strings.Index(lower[from:], "</"+tag)
+		if i < 0 {
+			return -1
+		}
+		i += from
+		after := i + 2 + len(tag)
+		if after >= len(s) || isTagNameEnd(s[after]) {
+			return i
+		}
+		from = i + 1
+	}
+}
+
+func (p *parser) readName() string {
+	start := p.pos
+	for p.pos < len(p.src) && !isTagNameEnd(p.src[p.pos]) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// readAttrs reads attributes up to the end of the start tag and reports
+// whether the tag was self-closing. Duplicate attributes keep their first
+// value, as in browsers.
+func (p *parser) readAttrs(n *Node) bool {
+	seen := map[string]bool{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return false
+		}
+		switch c := p.src[p.pos]; {
+		case c == '>':
+			p.pos++
+			return false
+		case c == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '>':
+			p.pos += 2
+			return true
+		case c == '/':
+			p.pos++
+			continue
+		}
+
+		start := p.pos
+		for p.pos < len(p.src) && !isSpace(p.src[p.pos]) && p.src[p.pos] != '>' && p.src[p.pos] != '=' &&
+			!(p.src[p.pos] == '/' && p.pos > start) {
+			p.pos++
+		}
+		attr := Attr{Name: strings.ToLower(p.src[start:p.pos])}
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '=' {
+			p.pos++
+			p.skipSpace()
+			attr.Value = html.UnescapeString(p.readAttrValue())
+			attr.HasValue = true
+		}
+		if !seen[attr.Name] {
+			seen[attr.Name] = true
+			n.Attrs = append(n.Attrs, attr)
+		}
+	}
+}
+
+func (p *parser) readAttrValue() string {
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	if q := p.src[p.pos]; q == '"' || q == '\'' {
+		end := strings.IndexByte(p.src[p.pos+1:], q)
+		if end < 0 {
+			v := p.src[p.pos+1:]
+			p.pos = len(p.src)
+			return v
+		}
+		v := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return v
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !isSpace(p.src[p.pos]) && p.src[p.pos] != '>' {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isTagNameEnd(c byte) bool {
+	return isSpace(c) || c == '>' || c == '/'
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
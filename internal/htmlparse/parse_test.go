@@ -0,0 +1,57 @@
+package htmlparse
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func render(nodes []elem.Node) string {
+	return elem.Fragment(nodes...).Render()
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"element", `<div class="a" id=b>Hi</div>`, `<div class="a" id="b">Hi</div>`},
+		{"nested", `<ul><li><a href="/x">x</a></li></ul>`, `<ul><li><a href="/x">x</a></li></ul>`},
+		{"void and self-closing", `<p>a<br/>b<img src='i.png'></p>`, `<p>a<br>b<img src="i.png"></p>`},
+		{"boolean attribute", `<input type="checkbox" checked>`, `<input checked type="checkbox">`},
+		{"uppercase", `<DIV CLASS="x">y</DIV>`, `<div class="x">y</div>`},
+		{"entities", `<p title="a &amp; &quot;b&quot;">1 &lt; 2 &copy;</p>`, `<p title="a &amp; &#34;b&#34;">1 &lt; 2 ©</p>`},
+		{"comment", `<!-- note --><p>x</p>`, `<!-- note --><p>x</p>`},
+		{"doctype skipped", `<!DOCTYPE html><p>x</p>`, `<p>x</p>`},
+		{"raw text", `<script>if (a < b) { x = "</p>" }</script>`, `<script>if (a < b) { x = "</p>" }</script>`},
+		{"implied li", `<ul><li>a<li>b</ul>`, `<ul><li>a</li><li>b</li></ul>`},
+		{"implied p", `<p>a<div>b</div>`, `<p>a</p><div>b</div>`},
+		{"implied td", `<table><tr><td>1<td>2<tr><td>3</table>`, `<table><tr><td>1</td><td>2</td></tr><tr><td>3</td></tr></table>`},
+		{"unclosed", `<div><span>x`, `<div><span>x</span></div>`},
+		{"stray end tag", `a</span>b`, `ab`},
+		{"literal less-than", `a < b`, `a &lt; b`},
+		{"duplicate attribute", `<a href="1" href="2">x</a>`, `<a href="1">x</a>`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, render(ToElem(Parse(tc.in))))
+		})
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	nodes := Parse(`<a href="/x" download>Link</a>`)
+	assert.Len(t, nodes, 1)
+	a := nodes[0]
+	assert.Equal(t, ElementNode, a.Type)
+	assert.Equal(t, "a", a.Tag)
+	assert.Equal(t, []Attr{{Name: "href", Value: "/x", HasValue: true}, {Name: "download"}}, a.Attrs)
+
+	href, ok := a.Attr("href")
+	assert.True(t, ok)
+	assert.Equal(t, "/x", href)
+	_, ok = a.Attr("title")
+	assert.False(t, ok)
+
+	assert.Equal(t, []*Node{{Type: TextNode, Data: "Link"}}, a.Children)
+}
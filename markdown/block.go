@@ -0,0 +1,661 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// htmlBlock is the kind of raw HTML blocks, which have no hook since they
+// are sanitized instead.
+const htmlBlock Kind = -1
+
+// block is a node of the block structure of a document. The inline content
+// of headings, paragraphs and table cells is parsed when converting.
+type block struct {
+	kind     Kind
+	level    int
+	text     string // inline source, code block content or raw HTML
+	info     string // code block language
+	children []*block
+
+	// Lists and list items.
+	ordered bool
+	start   int
+	tight   bool
+	task    bool
+	checked bool
+
+	// Tables: rows[0] is the header.
+	align []string
+	rows  [][]string
+}
+
+type linkRef struct {
+	dest, title string
+}
+
+type blockParser struct {
+	refs map[string]linkRef
+	html bool
+}
+
+// splitLines splits source into lines, normalizing line endings and
+// expanding tabs in indentation to spaces.
+func splitLines(source string) []string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\x00", "�")
+	lines := strings.Split(strings.TrimSuffix(source, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+	return lines
+}
+
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+	var b strings.Builder
+	col := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\t':
+			n := 4 - col%4
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+		case ' ':
+			b.WriteByte(' ')
+			col++
+		default:
+			// Only indentation matters for block structure.
+			b.WriteString(line[i:])
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// parse parses lines into blocks. It also reports whether blank lines
+// separate any of the blocks, which makes a list item loose.
+func (p *blockParser) parse(lines []string) ([]*block, bool) {
+	var (
+		blocks   []*block
+		para     []string
+		sawBlank bool
+		spaced   bool
+	)
+	add := func(b *block) {
+		if sawBlank && len(blocks) > 0 {
+			spaced = true
+		}
+		sawBlank = false
+		blocks = append(blocks, b)
+	}
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		if text := p.paragraph(para); text != "" {
+			add(&block{kind: Paragraph, text: text})
+		}
+		para = nil
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if isBlank(line) {
+			flush()
+			sawBlank = true
+			i++
+			continue
+		}
+
+		indent := indentation(line)
+		if indent >= 4 {
+			if len(para) > 0 {
+				// Indented code can't interrupt a paragraph.
+				para = append(para, line)
+				i++
+				continue
+			}
+			var b *block
+			b, i = codeBlockIndented(lines, i)
+			add(b)
+			continue
+		}
+
+		rest := line[indent:]
+		if level := setextLevel(rest); level > 0 && len(para) > 0 {
+			if text := p.paragraph(para); text != "" {
+				add(&block{kind: Heading, level: level, text: text})
+				para = nil
+				i++
+				continue
+			}
+			para = nil
+		}
+
+		switch {
+		case isThematicBreak(rest):
+			flush()
+			add(&block{kind: ThematicBreak})
+			i++
+		case atxHeading(rest) != nil:
+			flush()
+			add(atxHeading(rest))
+			i++
+		case fenceOpening(rest) != "":
+			flush()
+			var b *block
+			b, i = codeBlockFenced(lines, i)
+			add(b)
+		case rest[0] == '>':
+			flush()
+			var quoted []string
+			quoted, i = p.blockQuoteLines(lines, i)
+			children, _ := p.parse(quoted)
+			add(&block{kind: BlockQuote, children: children})
+		case p.html && startsHTMLBlock(rest, len(para) > 0):
+			flush()
+			var b *block
+			b, i = htmlBlockLines(lines, i)
+			add(b)
+		case startsList(line, len(para) > 0):
+			flush()
+			var b *block
+			b, i = p.list(lines, i)
+			add(b)
+		case isTableStart(lines, i):
+			flush()
+			var b *block
+			b, i = p.table(lines, i)
+			add(b)
+		default:
+			para = append(para, line)
+			i++
+		}
+	}
+	flush()
+	return blocks, spaced
+}
+
+// startsBlock reports whether line starts a block that interrupts a
+// paragraph, ending lazy continuation lines.
+func (p *blockParser) startsBlock(line string) bool {
+	indent := indentation(line)
+	if indent >= 4 {
+		return false
+	}
+	rest := line[indent:]
+	return isThematicBreak(rest) || atxHeading(rest) != nil || fenceOpening(rest) != "" ||
+		rest[0] == '>' || startsList(line, true) || (p.html && startsHTMLBlock(rest, true))
+}
+
+// paragraph extracts link reference definitions from the start of a
+// paragraph and returns the remaining text.
+func (p *blockParser) paragraph(lines []string) string {
+	for len(lines) > 0 {
+		m := linkRefDef.FindStringSubmatch(lines[0])
+		if m == nil {
+			break
+		}
+		label := normalizeLabel(m[1])
+		if _, exists := p.refs[label]; !exists && label != "" {
+			dest := strings.TrimSuffix(strings.TrimPrefix(m[2], "<"), ">")
+			title := ""
+			if len(m[3]) >= 2 {
+				title = m[3][1 : len(m[3])-1]
+			}
+			p.refs[label] = linkRef{dest: unescapeText(dest), title: unescapeText(title)}
+		}
+		lines = lines[1:]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimLeft(line, " ")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), " ")
+}
+
+var linkRefDef = regexp.MustCompile(`^ {0,3}\[((?:[^\]\\]|\\.){1,999})\]:[ ]*(<[^<>]*>|\S+)(?:[ ]+("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|\((?:[^()\\]|\\.)*\)))?[ ]*$`)
+
+// normalizeLabel matches link labels case-insensitively and ignoring
+// differences in whitespace.
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func isThematicBreak(s string) bool {
+	var char byte
+	count := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case ' ':
+		case '*', '-', '_':
+			if char != 0 && c != char {
+				return false
+			}
+			char = c
+			count++
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+func setextLevel(s string) int {
+	s = strings.TrimRight(s, " ")
+	switch {
+	case s == "":
+		return 0
+	case strings.Trim(s, "=") == "":
+		return 1
+	case strings.Trim(s, "-") == "":
+		return 2
+	}
+	return 0
+}
+
+func atxHeading(s string) *block {
+	level := 0
+	for level < len(s) && s[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(s) && s[level] != ' ') {
+		return nil
+	}
+	text := strings.TrimSpace(s[level:])
+	// Remove an optional closing sequence of #s.
+	if trimmed := strings.TrimRight(text, "#"); trimmed == "" {
+		text = ""
+	} else if strings.HasSuffix(trimmed, " ") {
+		text = strings.TrimRight(trimmed, " ")
+	}
+	return &block{kind: Heading, level: level, text: text}
+}
+
+// fenceOpening returns the fence that s opens a fenced code block with, or
+// "" if it doesn't.
+func fenceOpening(s string) string {
+	if len(s) < 3 || (s[0] != '`' && s[0] != '~') {
+		return ""
+	}
+	n := 0
+	for n < len(s) && s[n] == s[0] {
+		n++
+	}
+	if n < 3 || (s[0] == '`' && strings.Contains(s[n:], "`")) {
+		return ""
+	}
+	return s[:n]
+}
+
+func codeBlockFenced(lines []string, i int) (*block, int) {
+	indent := indentation(lines[i])
+	rest := lines[i][indent:]
+	fence := fenceOpening(rest)
+	info := unescapeText(strings.TrimSpace(rest[len(fence):]))
+	if f := strings.Fields(info); len(f) > 0 {
+		info = f[0]
+	}
+
+	var content []string
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if ind := indentation(line); ind < 4 {
+			closing := strings.TrimRight(line[ind:], " ")
+			if strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+				i++
+				break
+			}
+		}
+		// Remove up to the indentation of the opening fence.
+		strip := min(indent, indentation(line))
+		content = append(content, line[strip:])
+	}
+	return &block{kind: CodeBlock, info: info, text: codeText(content)}, i
+}
+
+func codeBlockIndented(lines []string, i int) (*block, int) {
+	var content []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) {
+			content = append(content, strings.TrimPrefix(line, strings.Repeat(" ", min(4, len(line)))))
+			continue
+		}
+		if indentation(line) < 4 {
+			break
+		}
+		content = append(content, line[4:])
+	}
+	// Trailing blank lines belong to whatever follows.
+	for len(content) > 0 && isBlank(content[len(content)-1]) {
+		content = content[:len(content)-1]
+	}
+	return &block{kind: CodeBlock, text: codeText(content)}, i
+}
+
+func codeText(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// blockQuoteLines returns the content of the block quote starting at
+// lines[i], without the > markers, and the index of the line after it.
+func (p *blockParser) blockQuoteLines(lines []string, i int) ([]string, int) {
+	var quoted []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		indent := indentation(line)
+		if indent < 4 && indent < len(line) && line[indent] == '>' {
+			rest := line[indent+1:]
+			quoted = append(quoted, strings.TrimPrefix(rest, " "))
+			continue
+		}
+		// Lazy continuation of a paragraph.
+		if !isBlank(line) && len(quoted) > 0 && !isBlank(quoted[len(quoted)-1]) && !p.startsBlock(line) {
+			quoted = append(quoted, line)
+			continue
+		}
+		break
+	}
+	return quoted, i
+}
+
+// listMarker describes the marker of a list item.
+type listMarker struct {
+	ordered bool
+	char    byte // bullet character or ordered delimiter
+	start   int
+	indent  int // of the content
+	content string
+}
+
+func parseListMarker(line string) (listMarker, bool) {
+	indent := indentation(line)
+	if indent >= 4 || indent == len(line) {
+		return listMarker{}, false
+	}
+	rest := line[indent:]
+	m := listMarker{}
+	width := 0
+	switch c := rest[0]; {
+	case c == '-' || c == '+' || c == '*':
+		m.char = c
+		width = 1
+	case c >= '0' && c <= '9':
+		for width < len(rest) && width < 9 && rest[width] >= '0' && rest[width] <= '9' {
+			width++
+		}
+		if width == len(rest) || (rest[width] != '.' && rest[width] != ')') {
+			return listMarker{}, false
+		}
+		m.ordered = true
+		m.start, _ = strconv.Atoi(rest[:width])
+		m.char = rest[width]
+		width++
+	default:
+		return listMarker{}, false
+	}
+
+	after := rest[width:]
+	if after != "" && after[0] != ' ' {
+		return listMarker{}, false
+	}
+	spaces := indentation(after)
+	if spaces > 4 || spaces == len(after) {
+		// Indented code or an empty item: the content starts after a single
+		// space.
+		spaces = min(1, len(after))
+	}
+	m.indent = indent + width + spaces
+	m.content = after[spaces:]
+	return m, true
+}
+
+// startsList reports whether line starts a list item. Inside a paragraph
+// only items that can interrupt it count: bullets and ordered items
+// starting at 1, with content.
+func startsList(line string, inParagraph bool) bool {
+	m, ok := parseListMarker(line)
+	if !ok {
+		return false
+	}
+	if inParagraph {
+		return !isBlank(m.content) && (!m.ordered || m.start == 1)
+	}
+	return true
+}
+
+func isListItem(line string) bool {
+	_, ok := parseListMarker(line)
+	return ok
+}
+
+func (p *blockParser) list(lines []string, i int) (*block, int) {
+	first, _ := parseListMarker(lines[i])
+	list := &block{kind: List, ordered: first.ordered, start: first.start, tight: true}
+
+	for i < len(lines) {
+		m, ok := parseListMarker(lines[i])
+		if !ok || m.ordered != first.ordered || m.char != first.char || isThematicBreak(strings.TrimLeft(lines[i], " ")) {
+			break
+		}
+
+		content := []string{m.content}
+		j := i + 1
+		for ; j < len(lines); j++ {
+			line := lines[j]
+			switch {
+			case isBlank(line):
+				content = append(content, "")
+				continue
+			case indentation(line) >= m.indent:
+				content = append(content, line[m.indent:])
+				continue
+			case isListItem(line):
+			case !isBlank(content[len(content)-1]) && !p.startsBlock(line):
+				content = append(content, line)
+				continue
+			}
+			break
+		}
+
+		trailing := 0
+		for len(content) > 1 && isBlank(content[len(content)-1]) {
+			content = content[:len(content)-1]
+			trailing++
+		}
+
+		item := &block{kind: ListItem}
+		var spaced bool
+		item.children, spaced = p.parse(content)
+		if spaced {
+			list.tight = false
+		}
+		if len(item.children) > 0 && item.children[0].kind == Paragraph {
+			item.task, item.checked, item.children[0].text = taskMarker(item.children[0].text)
+		}
+		list.children = append(list.children, item)
+		i = j
+
+		if trailing > 0 && i < len(lines) {
+			if next, ok := parseListMarker(lines[i]); ok && next.ordered == first.ordered && next.char == first.char {
+				list.tight = false
+			}
+		}
+	}
+	return list, i
+}
+
+// taskMarker detects a GFM task list marker at the start of an item.
+func taskMarker(text string) (task, checked bool, rest string) {
+	if len(text) < 4 || text[0] != '[' || text[2] != ']' || text[3] != ' ' {
+		return false, false, text
+	}
+	switch text[1] {
+	case ' ':
+		return true, false, strings.TrimLeft(text[4:], " ")
+	case 'x', 'X':
+		return true, true, strings.TrimLeft(text[4:], " ")
+	}
+	return false, false, text
+}
+
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") {
+		return false
+	}
+	align := tableAlign(lines[i+1])
+	return align != nil && len(splitRow(lines[i])) == len(align)
+}
+
+// tableAlign parses a table delimiter row, returning nil if line isn't one.
+func tableAlign(line string) []string {
+	if !strings.ContainsAny(line, "|:") || indentation(line) >= 4 {
+		return nil
+	}
+	cells := splitRow(line)
+	align := make([]string, len(cells))
+	for i, cell := range cells {
+		left, right := strings.HasPrefix(cell, ":"), strings.HasSuffix(cell, ":")
+		dashes := strings.TrimSuffix(strings.TrimPrefix(cell, ":"), ":")
+		if dashes == "" || strings.Trim(dashes, "-") != "" {
+			return nil
+		}
+		switch {
+		case left && right:
+			align[i] = "center"
+		case left:
+			align[i] = "left"
+		case right:
+			align[i] = "right"
+		}
+	}
+	return align
+}
+
+// splitRow splits a table row into its trimmed cells. Escaped pipes are
+// unescaped.
+func splitRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+func (p *blockParser) table(lines []string, i int) (*block, int) {
+	t := &block{kind: Table, align: tableAlign(lines[i+1])}
+	t.rows = append(t.rows, splitRow(lines[i]))
+	for i += 2; i < len(lines) && !isBlank(lines[i]) && !p.startsBlock(lines[i]); i++ {
+		t.rows = append(t.rows, splitRow(lines[i]))
+	}
+	return t, i
+}
+
+var (
+	htmlBlockRaw     = regexp.MustCompile(`^<(?i:script|pre|style|textarea)(?:\s|>|$)`)
+	htmlBlockComment = regexp.MustCompile(`^<!--`)
+	htmlBlockTag     = regexp.MustCompile(`^</?(?i:address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h[1-6]|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|search|section|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul)(?:\s|/?>|$)`)
+	htmlBlockOther   = regexp.MustCompile(`^(?:<[A-Za-z][A-Za-z0-9-]*(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*/?>|</[A-Za-z][A-Za-z0-9-]*\s*>)\s*$`)
+)
+
+func startsHTMLBlock(s string, inParagraph bool) bool {
+	_, ok := htmlBlockEnd(s, inParagraph)
+	return ok
+}
+
+// htmlBlockEnd reports whether s starts an HTML block and how the block
+// ends: at the line containing end, or at a blank line if end is empty.
+// Blocks of arbitrary tags can't interrupt a paragraph.
+func htmlBlockEnd(s string, inParagraph bool) (end string, ok bool) {
+	switch {
+	case htmlBlockRaw.MatchString(s):
+		name := strings.ToLower(strings.TrimLeft(s[:strings.IndexAny(s+" ", " >")], "<"))
+		return "</" + name + ">", true
+	case htmlBlockComment.MatchString(s):
+		return "-->", true
+	case htmlBlockTag.MatchString(s):
+		return "", true
+	case !inParagraph && htmlBlockOther.MatchString(s):
+		return "", true
+	}
+	return "", false
+}
+
+func htmlBlockLines(lines []string, i int) (*block, int) {
+	until, _ := htmlBlockEnd(strings.TrimLeft(lines[i], " "), false)
+	var raw []string
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if until == "" && isBlank(line) {
+			break
+		}
+		raw = append(raw, line)
+		if until != "" && strings.Contains(strings.ToLower(line), until) {
+			i++
+			break
+		}
+	}
+	return &block{kind: htmlBlock, text: strings.Join(raw, "\n")}, i
+}
+
+// unescapeText resolves backslash escapes and character references.
+func unescapeText(s string) string {
+	if !strings.ContainsAny(s, `\&`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			b.WriteByte(s[i+1])
+			i++
+			continue
+		}
+		if s[i] == '&' {
+			if ref := entity.FindString(s[i:]); ref != "" {
+				b.WriteString(html.UnescapeString(ref))
+				i += len(ref) - 1
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+var entity = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
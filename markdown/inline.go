@@ -0,0 +1,782 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+)
+
+// item is an entry in the doubly linked list an inline parser builds: text,
+// a finished node, a delimiter run of *, _ or ~, a link or image opener
+// ([ or ![), or a raw HTML tag. Delimiters and openers that don't end up
+// matched are rendered as the literal text they came from.
+type item struct {
+	prev, next *item
+
+	text string
+	node elem.Node
+
+	delim             byte
+	count, origCount  int
+	canOpen, canClose bool
+	// Delimiter runs are also linked to each other, so that matching them
+	// doesn't walk over the nodes built in between.
+	prevDelim, nextDelim *item
+
+	// pos is the source offset of a delimiter run, or the offset after the
+	// bracket of a link or image opener.
+	pos      int
+	inactive bool
+	// delimsBefore is the last delimiter run before a link or image opener.
+	delimsBefore *item
+
+	// autolink is set for links found in plain text, which turn back into
+	// text inside the text of another link.
+	autolink bool
+
+	tag *htmlTag
+}
+
+// htmlTag is a raw inline HTML tag, matched with its end tag and sanitized
+// when the nodes are built.
+type htmlTag struct {
+	name    string
+	closing bool
+	void    bool
+	raw     string
+}
+
+type inlineParser struct {
+	c          *converter
+	src        string
+	pos        int
+	head, tail *item
+	brackets   []*item // link and image openers, innermost last
+	lastDelim  *item
+}
+
+// specials are the characters that may start inline syntax.
+const specials = "\\`*_~[]!<&\nhw"
+
+// inline parses the inline content of a block into nodes.
+func (c *converter) inline(src string) []elem.Node {
+	p := &inlineParser{c: c, src: src}
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '\\':
+			p.backslash()
+		case '`':
+			p.codeSpan()
+		case '*', '_', '~':
+			p.delimiterRun()
+		case '[':
+			p.openBracket('[', p.pos+1)
+		case '!':
+			if strings.HasPrefix(p.src[p.pos:], "![") {
+				p.openBracket('!', p.pos+2)
+			} else {
+				p.plainText()
+			}
+		case ']':
+			p.closeBracket()
+		case '<':
+			p.angleBracket()
+		case '&':
+			p.entity()
+		case '\n':
+			p.lineBreak()
+		case 'h', 'w':
+			if !p.extendedAutolink() {
+				p.plainText()
+			}
+		default:
+			p.plainText()
+		}
+	}
+	p.processEmphasis(nil)
+	return c.nodes(p.head, nil)
+}
+
+func (p *inlineParser) append(it *item) {
+	it.prev = p.tail
+	if p.tail != nil {
+		p.tail.next = it
+	} else {
+		p.head = it
+	}
+	p.tail = it
+}
+
+func (p *inlineParser) remove(it *item) {
+	if it.prev != nil {
+		it.prev.next = it.next
+	} else {
+		p.head = it.next
+	}
+	if it.next != nil {
+		it.next.prev = it.prev
+	} else {
+		p.tail = it.prev
+	}
+}
+
+func isText(it *item) bool {
+	return it != nil && it.node == nil && it.delim == 0 && it.tag == nil
+}
+
+// addText appends text. Adjacent text items are joined when the nodes are
+// built rather than here, which would copy long paragraphs over and over.
+func (p *inlineParser) addText(s string) {
+	p.append(&item{text: s})
+}
+
+func (p *inlineParser) addNode(n elem.Node) {
+	p.append(&item{node: n})
+}
+
+// plainText consumes text up to the next character that may start inline
+// syntax.
+func (p *inlineParser) plainText() {
+	start := p.pos
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		c := p.src[p.pos]
+		if strings.IndexByte(specials, c) < 0 {
+			continue
+		}
+		if (c == 'h' || c == 'w') && !autolinkBoundary(p.src[p.pos-1]) {
+			continue
+		}
+		break
+	}
+	p.addText(p.src[start:p.pos])
+}
+
+func (p *inlineParser) backslash() {
+	if p.pos+1 < len(p.src) {
+		next := p.src[p.pos+1]
+		if next == '\n' {
+			p.pos++
+			p.hardBreak()
+			return
+		}
+		if isASCIIPunct(next) {
+			p.addText(string(next))
+			p.pos += 2
+			return
+		}
+	}
+	p.addText(`\`)
+	p.pos++
+}
+
+func (p *inlineParser) codeSpan() {
+	start := p.pos
+	n := runLength(p.src, start)
+	after := start + n
+
+	for i := after; i < len(p.src); {
+		j := strings.IndexByte(p.src[i:], '`')
+		if j < 0 {
+			break
+		}
+		j += i
+		if m := runLength(p.src, j); m != n {
+			i = j + m
+			continue
+		}
+		content := strings.ReplaceAll(p.src[after:j], "\n", " ")
+		if len(content) >= 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.Trim(content, " ") != "" {
+			content = content[1 : len(content)-1]
+		}
+		p.addNode(p.c.node(Construct{Kind: CodeSpan, Text: content}, elem.Code(nil, elem.Text(content))))
+		p.pos = j + n
+		return
+	}
+	// No closing run: the backticks are literal.
+	p.addText(p.src[start:after])
+	p.pos = after
+}
+
+func runLength(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// delimiterRun adds a run of *, _ or ~, determining whether it can open or
+// close emphasis from the characters around it.
+func (p *inlineParser) delimiterRun() {
+	c := p.src[p.pos]
+	n := runLength(p.src, p.pos)
+	if c == '~' && n > 2 {
+		p.addText(p.src[p.pos : p.pos+n])
+		p.pos += n
+		return
+	}
+
+	before, after := ' ', ' '
+	if p.pos > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.src[:p.pos])
+	}
+	if p.pos+n < len(p.src) {
+		after, _ = utf8.DecodeRuneInString(p.src[p.pos+n:])
+	}
+	left := !unicode.IsSpace(after) && (!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	right := !unicode.IsSpace(before) && (!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	it := &item{delim: c, count: n, origCount: n, canOpen: left, canClose: right, pos: p.pos}
+	if c == '_' {
+		it.canOpen = left && (!right || isPunct(before))
+		it.canClose = right && (!left || isPunct(after))
+	}
+	p.append(it)
+	it.prevDelim = p.lastDelim
+	if p.lastDelim != nil {
+		p.lastDelim.nextDelim = it
+	}
+	p.lastDelim = it
+	p.pos += n
+}
+
+// removeDelim unlinks a delimiter run from the other runs.
+func (p *inlineParser) removeDelim(it *item) {
+	if it.prevDelim != nil {
+		it.prevDelim.nextDelim = it.nextDelim
+	}
+	if it.nextDelim != nil {
+		it.nextDelim.prevDelim = it.prevDelim
+	} else {
+		p.lastDelim = it.prevDelim
+	}
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// processEmphasis matches the delimiter runs after bottom, or all of them
+// if bottom is nil, turning them into emphasis, strong emphasis and
+// strikethrough nodes. Afterwards no delimiter runs after bottom remain.
+func (p *inlineParser) processEmphasis(bottom *item) {
+	var stop *item
+	if bottom != nil {
+		stop = bottom.delimsBefore
+	}
+	var first *item
+	for d := p.lastDelim; d != stop; d = d.prevDelim {
+		first = d
+	}
+	// openersBottom remembers the position of closers for which the search
+	// for an opener failed, so it isn't repeated for later closers of the
+	// same kind. Positions are used because matching removes runs.
+	type closerKind struct {
+		delim   byte
+		canOpen bool
+		mod3    int
+	}
+	openersBottom := map[closerKind]int{}
+
+	for closer := first; closer != nil; {
+		if !closer.canClose {
+			closer = closer.nextDelim
+			continue
+		}
+		kind := closerKind{closer.delim, closer.canOpen, closer.origCount % 3}
+		limit, limited := openersBottom[kind]
+		var opener *item
+		for o := closer.prevDelim; o != stop && (!limited || o.pos >= limit); o = o.prevDelim {
+			if o.delim != closer.delim || !o.canOpen {
+				continue
+			}
+			if closer.delim == '~' {
+				if o.count == closer.count {
+					opener = o
+					break
+				}
+				continue
+			}
+			// The "rule of three" keeps **a*b** from matching the inner
+			// delimiter.
+			if (o.canClose || closer.canOpen) && (o.origCount+closer.origCount)%3 == 0 &&
+				(o.origCount%3 != 0 || closer.origCount%3 != 0) {
+				continue
+			}
+			opener = o
+			break
+		}
+		if opener == nil {
+			openersBottom[kind] = closer.pos
+			closer = closer.nextDelim
+			continue
+		}
+
+		use, construct, tag := 1, Emphasis, "em"
+		switch {
+		case closer.delim == '~':
+			use, construct, tag = closer.count, Strikethrough, "del"
+		case opener.count >= 2 && closer.count >= 2:
+			use, construct, tag = 2, Strong, "strong"
+		}
+		opener.count -= use
+		closer.count -= use
+
+		// Runs between the two are left as text.
+		for d := opener.nextDelim; d != closer; d = d.nextDelim {
+			p.removeDelim(d)
+		}
+		children := p.c.nodes(opener.next, closer)
+		it := &item{node: p.c.node(Construct{Kind: construct}, elem.NewElement(tag, nil, children...)), prev: opener, next: closer}
+		opener.next = it
+		closer.prev = it
+
+		if opener.count == 0 {
+			p.remove(opener)
+			p.removeDelim(opener)
+		}
+		if closer.count == 0 {
+			next := closer.nextDelim
+			p.remove(closer)
+			p.removeDelim(closer)
+			closer = next
+		}
+	}
+
+	p.lastDelim = stop
+	if stop != nil {
+		stop.nextDelim = nil
+	}
+}
+
+func (p *inlineParser) openBracket(delim byte, end int) {
+	it := &item{delim: delim, pos: end, delimsBefore: p.lastDelim}
+	p.append(it)
+	p.brackets = append(p.brackets, it)
+	p.pos = end
+}
+
+// closeBracket handles a ], turning it and the last opener into a link or
+// image if a destination or a defined reference follows.
+func (p *inlineParser) closeBracket() {
+	labelEnd := p.pos
+	p.pos++
+	if len(p.brackets) == 0 {
+		p.addText("]")
+		return
+	}
+	opener := p.brackets[len(p.brackets)-1]
+	p.brackets = p.brackets[:len(p.brackets)-1]
+
+	dest, title, end, ok := p.inlineLink(p.pos)
+	if !ok {
+		var ref linkRef
+		ref, end, ok = p.referenceLink(p.pos, p.src[opener.pos:labelEnd])
+		dest, title = ref.dest, ref.title
+	}
+	if !ok || opener.inactive {
+		opener.text = "["
+		if opener.delim == '!' {
+			opener.text = "!["
+		}
+		opener.delim = 0
+		p.addText("]")
+		return
+	}
+	p.pos = end
+
+	p.processEmphasis(opener)
+	for it := opener.next; it != nil; it = it.next {
+		if it.autolink {
+			it.node = nil
+		}
+	}
+	children := p.c.nodes(opener.next, nil)
+	image := opener.delim == '!'
+
+	p.tail = opener.prev
+	if p.tail != nil {
+		p.tail.next = nil
+	} else {
+		p.head = nil
+	}
+	p.addNode(p.c.link(dest, title, children, image))
+
+	// Links can't contain other links. Openers before an inactive one are
+	// inactive already.
+	if !image {
+		for i := len(p.brackets) - 1; i >= 0 && !p.brackets[i].inactive; i-- {
+			if p.brackets[i].delim == '[' {
+				p.brackets[i].inactive = true
+			}
+		}
+	}
+}
+
+// inlineLink parses the (destination "title") of an inline link at i.
+func (p *inlineParser) inlineLink(i int) (dest, title string, end int, ok bool) {
+	s := p.src
+	if i >= len(s) || s[i] != '(' {
+		return "", "", 0, false
+	}
+	i = skipLinkSpace(s, i+1)
+
+	// Destination, either in <> or with balanced parentheses.
+	if i < len(s) && s[i] == '<' {
+		j := i + 1
+		for ; j < len(s) && s[j] != '>'; j++ {
+			if s[j] == '\n' || s[j] == '<' {
+				return "", "", 0, false
+			}
+			if s[j] == '\\' && j+1 < len(s) {
+				j++
+			}
+		}
+		if j >= len(s) {
+			return "", "", 0, false
+		}
+		dest = s[i+1 : j]
+		i = j + 1
+	} else {
+		start, depth := i, 0
+	scan:
+		for ; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]):
+				i++
+			case c == '(':
+				// Like other implementations, limit nesting to keep
+				// unbalanced input from being rescanned over and over.
+				if depth++; depth > 32 {
+					return "", "", 0, false
+				}
+			case c == ')':
+				if depth == 0 {
+					break scan
+				}
+				depth--
+			case c <= ' ':
+				break scan
+			}
+		}
+		if depth != 0 {
+			return "", "", 0, false
+		}
+		dest = s[start:i]
+	}
+
+	// Optional title, separated from the destination by whitespace.
+	j := skipLinkSpace(s, i)
+	if j < len(s) && j > i && (s[j] == '"' || s[j] == '\'' || s[j] == '(') {
+		closeChar := s[j]
+		if closeChar == '(' {
+			closeChar = ')'
+		}
+		k := j + 1
+		for ; k < len(s) && s[k] != closeChar; k++ {
+			if s[k] == '\\' && k+1 < len(s) {
+				k++
+			}
+		}
+		if k >= len(s) {
+			return "", "", 0, false
+		}
+		title = s[j+1 : k]
+		j = skipLinkSpace(s, k+1)
+	}
+	if j >= len(s) || s[j] != ')' {
+		return "", "", 0, false
+	}
+	return unescapeText(dest), unescapeText(title), j + 1, true
+}
+
+func skipLinkSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// referenceLink resolves a full ([text][label]), collapsed ([text][]) or
+// shortcut ([text]) reference link whose text is label.
+func (p *inlineParser) referenceLink(i int, text string) (linkRef, int, bool) {
+	label := text
+	end := i
+	if i < len(p.src) && p.src[i] == '[' {
+		j := strings.IndexAny(p.src[i+1:], "[]")
+		if j >= 0 && p.src[i+1+j] == ']' {
+			if j > 0 {
+				label = p.src[i+1 : i+1+j]
+			}
+			end = i + j + 2
+		}
+	}
+	// Definitions can't have longer labels, so there's no need to
+	// normalize them.
+	if len(label) > 999 {
+		return linkRef{}, end, false
+	}
+	ref, ok := p.c.refs[normalizeLabel(label)]
+	return ref, end, ok
+}
+
+var (
+	autolinkURI   = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9.+-]{1,31}:[^\s<>]*>`)
+	autolinkEmail = regexp.MustCompile(`^<[a-zA-Z0-9.!#$%&'*+/=?^_` + "`" + `{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*>`)
+	inlineTag     = regexp.MustCompile(`^(?:<([A-Za-z][A-Za-z0-9-]*)(?:\s+[A-Za-z_:][A-Za-z0-9_.:-]*(?:\s*=\s*(?:[^\s"'=<>` + "`" + `]+|'[^']*'|"[^"]*"))?)*\s*(/?)>|</([A-Za-z][A-Za-z0-9-]*)\s*>)`)
+	inlineComment = regexp.MustCompile(`^<!--(?s:.*?)-->`)
+	extAutolink   = regexp.MustCompile(`^(?:https?://|www\.)[A-Za-z0-9][^\s<]*`)
+)
+
+func (p *inlineParser) angleBracket() {
+	rest := p.src[p.pos:]
+	if m := autolinkURI.FindString(rest); m != "" {
+		u := m[1 : len(m)-1]
+		p.addNode(p.c.link(u, "", []elem.Node{elem.Text(u)}, false))
+		p.pos += len(m)
+		return
+	}
+	if m := autolinkEmail.FindString(rest); m != "" {
+		addr := m[1 : len(m)-1]
+		p.addNode(p.c.link("mailto:"+addr, "", []elem.Node{elem.Text(addr)}, false))
+		p.pos += len(m)
+		return
+	}
+	if p.c.opts.AllowHTML {
+		if m := inlineComment.FindString(rest); m != "" {
+			p.pos += len(m)
+			return
+		}
+		if m := inlineTag.FindStringSubmatch(rest); m != nil {
+			tag := &htmlTag{raw: m[0], name: strings.ToLower(m[1]), void: m[2] == "/"}
+			if m[3] != "" {
+				tag.name, tag.closing = strings.ToLower(m[3]), true
+			}
+			tag.void = tag.void || elem.IsVoidElement(tag.name)
+			p.append(&item{tag: tag})
+			p.pos += len(m[0])
+			return
+		}
+	}
+	p.addText("<")
+	p.pos++
+}
+
+func (p *inlineParser) entity() {
+	if m := entity.FindString(p.src[p.pos:]); m != "" {
+		p.addText(html.UnescapeString(m))
+		p.pos += len(m)
+		return
+	}
+	p.addText("&")
+	p.pos++
+}
+
+// lineBreak handles a line ending: a hard break if the line ends with two
+// or more spaces, a soft break otherwise.
+func (p *inlineParser) lineBreak() {
+	hard := false
+	if isText(p.tail) {
+		trimmed := strings.TrimRight(p.tail.text, " ")
+		hard = len(p.tail.text)-len(trimmed) >= 2
+		p.tail.text = trimmed
+	}
+	if hard {
+		p.hardBreak()
+		return
+	}
+	p.addText("\n")
+	p.pos++
+	p.skipSpaces()
+}
+
+func (p *inlineParser) hardBreak() {
+	p.addNode(p.c.node(Construct{Kind: LineBreak}, elem.Br(nil)))
+	p.pos++
+	p.skipSpaces()
+}
+
+func (p *inlineParser) skipSpaces() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func autolinkBoundary(c byte) bool {
+	return strings.IndexByte(" \n*_~(", c) >= 0
+}
+
+// extendedAutolink links a URL starting with http://, https:// or www. in
+// plain text, as GitHub does.
+func (p *inlineParser) extendedAutolink() bool {
+	if p.pos > 0 && !autolinkBoundary(p.src[p.pos-1]) {
+		return false
+	}
+	m := extAutolink.FindString(p.src[p.pos:])
+	if m == "" {
+		return false
+	}
+	// Trailing punctuation and unbalanced closing parentheses end the link.
+	for len(m) > 0 {
+		last := m[len(m)-1]
+		if strings.IndexByte("?!.,:*_~'\"", last) >= 0 ||
+			(last == ')' && strings.Count(m, ")") > strings.Count(m, "(")) {
+			m = m[:len(m)-1]
+			continue
+		}
+		break
+	}
+	href := m
+	if strings.HasPrefix(m, "www.") {
+		href = "http://" + m
+	}
+	p.append(&item{node: p.c.link(href, "", []elem.Node{elem.Text(m)}, false), autolink: true, text: m})
+	p.pos += len(m)
+	return true
+}
+
+// link builds a link or image node. Destinations the policy doesn't allow
+// are left out.
+func (c *converter) link(dest, title string, children []elem.Node, image bool) elem.Node {
+	con := Construct{Kind: Link, Text: plainText(children), Title: title}
+	props := attrs.Props{}
+	urlAttr := attrs.Href
+	if image {
+		con.Kind = Image
+		urlAttr = attrs.Src
+		props[attrs.Alt] = html.EscapeString(con.Text)
+	}
+	if u, ok := c.policy.SanitizeURL(dest); ok {
+		con.Destination = u
+		props[urlAttr] = html.EscapeString(strings.ReplaceAll(u, " ", "%20"))
+	}
+	if title != "" {
+		props[attrs.Title] = html.EscapeString(title)
+	}
+	if image {
+		return c.node(con, elem.Img(props))
+	}
+	return c.node(con, elem.A(props, children...))
+}
+
+// nodes builds the nodes for the items from first up to, but not
+// including, last. Unmatched delimiters become text, and raw HTML tags are
+// matched with their end tags and sanitized.
+func (c *converter) nodes(first, last *item) []elem.Node {
+	b := nodeBuilder{c: c}
+	b.matchTags(first, last)
+	return b.build(first, last)
+}
+
+// nodeBuilder builds nodes from items, knowing the end tag of each raw HTML
+// start tag up front so that unmatched tags don't each search the rest of
+// the items.
+type nodeBuilder struct {
+	c *converter
+	// ends maps start tags to their end tags, and index gives the order of
+	// the items, which tells whether an end tag is within a range.
+	ends  map[*item]*item
+	index map[*item]int
+}
+
+// matchTags pairs each start tag with the next end tag of the same name
+// that isn't closing a nested element of that name.
+func (b *nodeBuilder) matchTags(first, last *item) {
+	var open map[string][]*item
+	i := 0
+	for it := first; it != last && it != nil; it = it.next {
+		i++
+		tag := it.tag
+		if tag == nil || tag.void {
+			continue
+		}
+		if open == nil {
+			open = map[string][]*item{}
+			b.ends = map[*item]*item{}
+			b.index = map[*item]int{}
+		}
+		b.index[it] = i
+		stack := open[tag.name]
+		if !tag.closing {
+			open[tag.name] = append(stack, it)
+		} else if len(stack) > 0 {
+			b.ends[stack[len(stack)-1]] = it
+			open[tag.name] = stack[:len(stack)-1]
+		}
+	}
+	if last != nil && b.index != nil {
+		b.index[last] = i + 1
+	}
+}
+
+func (b *nodeBuilder) build(first, last *item) []elem.Node {
+	var out []elem.Node
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			out = append(out, elem.Text(text.String()))
+			text.Reset()
+		}
+	}
+	for it := first; it != last && it != nil; it = it.next {
+		switch {
+		case it.tag != nil:
+			flush()
+			var nodes []elem.Node
+			nodes, it = b.rawTag(it, last)
+			out = append(out, nodes...)
+		case it.node != nil:
+			flush()
+			out = append(out, it.node)
+		case it.delim == '[':
+			text.WriteString("[")
+		case it.delim == '!':
+			text.WriteString("![")
+		case it.delim != 0:
+			text.WriteString(strings.Repeat(string(it.delim), it.count))
+		default:
+			text.WriteString(it.text)
+		}
+	}
+	flush()
+	return out
+}
+
+// rawTag sanitizes the raw HTML element started by open, whose content are
+// the items up to its end tag. It returns the resulting nodes and the last
+// item consumed. Stray end tags and start tags without an end tag before
+// last are dropped.
+func (b *nodeBuilder) rawTag(open, last *item) ([]elem.Node, *item) {
+	tag := open.tag
+	if tag.closing {
+		return nil, open
+	}
+	if tag.void {
+		return b.c.policy.Sanitize(tag.raw).Children, open
+	}
+
+	end := b.ends[open]
+	if end == nil || (last != nil && b.index[end] > b.index[last]) {
+		return nil, open
+	}
+	// The element is sanitized with placeholder content, to learn whether
+	// the policy keeps it, unwraps its content, or removes it along with its
+	// content as it does script and style elements.
+	sanitized := b.c.policy.Sanitize(tag.raw + "-</" + tag.name + ">").Children
+	switch {
+	case len(sanitized) == 0:
+		return nil, end
+	case len(sanitized) == 1:
+		if e, ok := sanitized[0].(*elem.Element); ok && e.Tag == tag.name {
+			e.Children = b.build(open.next, end)
+			return []elem.Node{e}, end
+		}
+	}
+	// The element isn't allowed, but its content is.
+	return b.build(open.next, end), end
+}
@@ -0,0 +1,249 @@
+// Package markdown converts markdown into elem nodes: CommonMark plus the
+// GitHub Flavored Markdown tables, task lists, strikethrough and autolinks.
+//
+// The result is an ordinary element tree built from elem.H1, elem.Ul,
+// elem.Pre, elem.Table and friends, so it can be styled, inspected and
+// embedded like hand-written nodes. Hooks customize the node produced for
+// each construct. Raw HTML in the markdown is rendered as literal text
+// unless it is allowed, in which case it is sanitized.
+package markdown
+
+import (
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/chasefleming/elem-go/sanitize"
+)
+
+// Kind identifies a markdown construct.
+type Kind int
+
+const (
+	Heading Kind = iota
+	Paragraph
+	BlockQuote
+	List
+	ListItem
+	CodeBlock
+	ThematicBreak
+	Table
+	TableRow
+	TableCell
+	Emphasis
+	Strong
+	Strikethrough
+	CodeSpan
+	Link
+	Image
+	LineBreak
+)
+
+// Construct describes the markdown construct a Hook is called for. Only the
+// fields relevant to its Kind are set.
+type Construct struct {
+	Kind Kind
+	// Level is the level of a Heading, from 1 to 6.
+	Level int
+	// Text is the plain text of a Heading, Link or Image, or the content of
+	// a CodeBlock or CodeSpan.
+	Text string
+	// Language is the first word of the info string of a fenced CodeBlock.
+	Language string
+	// Destination and Title are the unescaped URL and title of a Link or
+	// Image. Destination is empty if the URL was not allowed by the policy.
+	Destination string
+	Title       string
+	// Ordered and Start describe a List.
+	Ordered bool
+	Start   int
+	// Task and Checked describe a task ListItem.
+	Task    bool
+	Checked bool
+	// Header is set for the cells and the row of a table's header.
+	Header bool
+	// Align is "left", "center", "right" or empty for a TableCell.
+	Align string
+}
+
+// Hook customizes the node produced for a construct. It receives the
+// default element, whose Attrs are never nil, and returns the node to use
+// instead, which may be the same element after modifying it.
+type Hook func(c Construct, e *elem.Element) elem.Node
+
+// Options configure Convert.
+type Options struct {
+	// Hooks maps constructs to the hook customizing their nodes.
+	Hooks map[Kind]Hook
+	// AllowHTML enables raw HTML in the markdown. It is sanitized with
+	// Policy. Otherwise raw HTML is rendered as the literal text it is.
+	AllowHTML bool
+	// Policy sanitizes raw HTML and checks the URLs of links and images.
+	// Its SetAttrs are set on every element, whether written in markdown or
+	// in HTML. Defaults to sanitize.UGCPolicy().
+	Policy *sanitize.Policy
+}
+
+// Convert parses source as markdown and returns the resulting nodes as a
+// fragment.
+func Convert(source string, opts Options) *elem.Element {
+	c := &converter{opts: opts, refs: map[string]linkRef{}}
+	if opts.Policy != nil {
+		c.policy = *opts.Policy
+	} else {
+		c.policy = sanitize.UGCPolicy()
+	}
+	bp := blockParser{refs: c.refs, html: opts.AllowHTML}
+	blocks, _ := bp.parse(splitLines(source))
+	return elem.Fragment(c.blocks(blocks, false)...)
+}
+
+type converter struct {
+	opts   Options
+	policy sanitize.Policy
+	refs   map[string]linkRef
+}
+
+// node applies the policy's SetAttrs and then the hook for c.Kind, if any,
+// to the default element e.
+func (c *converter) node(con Construct, e *elem.Element) elem.Node {
+	c.setAttrs(e)
+	if hook := c.opts.Hooks[con.Kind]; hook != nil {
+		return hook(con, e)
+	}
+	return e
+}
+
+// setAttrs sets the attributes the policy sets on every element of e's tag,
+// as sanitizing raw HTML does, so that e.g. links written in markdown get
+// rel="nofollow noopener" like raw HTML links. It returns e.
+func (c *converter) setAttrs(e *elem.Element) *elem.Element {
+	if e.Attrs == nil {
+		e.Attrs = attrs.Props{}
+	}
+	for name, value := range c.policy.SetAttrs[e.Tag] {
+		e.Attrs[name] = value
+	}
+	return e
+}
+
+func (c *converter) blocks(blocks []*block, tight bool) []elem.Node {
+	var out []elem.Node
+	for _, b := range blocks {
+		if tight && b.kind == Paragraph {
+			out = append(out, c.inline(b.text)...)
+			continue
+		}
+		out = append(out, c.block(b))
+	}
+	return out
+}
+
+func (c *converter) block(b *block) elem.Node {
+	switch b.kind {
+	case Heading:
+		children := c.inline(b.text)
+		con := Construct{Kind: Heading, Level: b.level, Text: plainText(children)}
+		return c.node(con, elem.NewElement("h"+strconv.Itoa(b.level), nil, children...))
+	case Paragraph:
+		return c.node(Construct{Kind: Paragraph}, elem.P(nil, c.inline(b.text)...))
+	case BlockQuote:
+		return c.node(Construct{Kind: BlockQuote}, elem.Blockquote(nil, c.blocks(b.children, false)...))
+	case List:
+		return c.list(b)
+	case CodeBlock:
+		codeAttrs := attrs.Props{}
+		if b.info != "" {
+			codeAttrs[attrs.Class] = "language-" + html.EscapeString(b.info)
+		}
+		con := Construct{Kind: CodeBlock, Text: b.text, Language: b.info}
+		return c.node(con, elem.Pre(nil, c.setAttrs(elem.Code(codeAttrs, elem.Text(b.text)))))
+	case ThematicBreak:
+		return c.node(Construct{Kind: ThematicBreak}, elem.Hr(nil))
+	case Table:
+		return c.table(b)
+	case htmlBlock:
+		return c.policy.Sanitize(b.text)
+	}
+	return elem.None()
+}
+
+func (c *converter) list(b *block) elem.Node {
+	items := make([]elem.Node, 0, len(b.children))
+	for _, item := range b.children {
+		con := Construct{Kind: ListItem, Task: item.task, Checked: item.checked}
+		children := c.blocks(item.children, b.tight)
+		if item.task {
+			box := attrs.Props{attrs.Type: "checkbox", attrs.Disabled: "true"}
+			if item.checked {
+				box[attrs.Checked] = "true"
+			}
+			children = append([]elem.Node{c.setAttrs(elem.Input(box)), elem.Text(" ")}, children...)
+		}
+		items = append(items, c.node(con, elem.Li(nil, children...)))
+	}
+
+	con := Construct{Kind: List, Ordered: b.ordered, Start: b.start}
+	if !b.ordered {
+		return c.node(con, elem.Ul(nil, items...))
+	}
+	list := elem.Ol(nil, items...)
+	if b.start != 1 {
+		list.Attrs = attrs.Props{"start": strconv.Itoa(b.start)}
+	}
+	return c.node(con, list)
+}
+
+func (c *converter) table(b *block) elem.Node {
+	row := func(cells []string, header bool) elem.Node {
+		nodes := make([]elem.Node, len(b.align))
+		for i := range b.align {
+			var text string
+			if i < len(cells) {
+				text = cells[i]
+			}
+			tag := "td"
+			if header {
+				tag = "th"
+			}
+			cell := elem.NewElement(tag, nil, c.inline(text)...)
+			if b.align[i] != "" {
+				cell.Attrs = attrs.Props{attrs.Style: "text-align: " + b.align[i]}
+			}
+			nodes[i] = c.node(Construct{Kind: TableCell, Header: header, Align: b.align[i]}, cell)
+		}
+		return c.node(Construct{Kind: TableRow, Header: header}, elem.Tr(nil, nodes...))
+	}
+
+	table := elem.Table(nil, c.setAttrs(elem.THead(nil, row(b.rows[0], true))))
+	if len(b.rows) > 1 {
+		body := c.setAttrs(elem.TBody(nil))
+		for _, cells := range b.rows[1:] {
+			body.Children = append(body.Children, row(cells, false))
+		}
+		table.Children = append(table.Children, body)
+	}
+	return c.node(Construct{Kind: Table}, table)
+}
+
+// plainText returns the text content of nodes, as used for image
+// descriptions and Construct.Text.
+func plainText(nodes []elem.Node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		elem.Walk(n, func(n elem.Node) bool {
+			switch n := n.(type) {
+			case elem.TextNode:
+				b.WriteString(string(n))
+			case *elem.Element:
+				if n.Tag == "img" {
+					b.WriteString(html.UnescapeString(n.Attrs[attrs.Alt]))
+				}
+			}
+			return true
+		})
+	}
+	return b.String()
+}
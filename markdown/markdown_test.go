@@ -0,0 +1,150 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/chasefleming/elem-go/sanitize"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertBlocks(t *testing.T) {
+	cases := []struct {
+		name, input, want string
+	}{
+		{"atx headings", "# One #\n### Three", "<h1>One</h1><h3>Three</h3>"},
+		{"setext headings", "One\n===\nTwo\n---", "<h1>One</h1><h2>Two</h2>"},
+		{"paragraphs", "a\nb\n\nc", "<p>a\nb</p><p>c</p>"},
+		{"thematic breaks", "***\n- - -\n___", "<hr><hr><hr>"},
+		{"fenced code", "```go\nif a < b {}\n```", "<pre><code class=\"language-go\">if a &lt; b {}\n</code></pre>"},
+		{"tilde fence", "~~~\n```\n~~~", "<pre><code>```\n</code></pre>"},
+		{"indented code", "    a\n\n    b", "<pre><code>a\n\nb\n</code></pre>"},
+		{"block quote", "> a\nlazy\n> > b", "<blockquote><p>a\nlazy</p><blockquote><p>b</p></blockquote></blockquote>"},
+		{"tight list", "- a\n- b\n  - c", "<ul><li>a</li><li>b<ul><li>c</li></ul></li></ul>"},
+		{"loose list", "- a\n\n- b", "<ul><li><p>a</p></li><li><p>b</p></li></ul>"},
+		{"ordered list", "3) a\n4) b", "<ol start=\"3\"><li>a</li><li>b</li></ol>"},
+		{"list types", "- a\n+ b", "<ul><li>a</li></ul><ul><li>b</li></ul>"},
+		{"list interrupting", "text\n2. no\n- yes", "<p>text\n2. no</p><ul><li>yes</li></ul>"},
+		{"task list", "- [ ] todo\n- [x] done", "<ul><li><input disabled type=\"checkbox\"> todo</li><li><input checked disabled type=\"checkbox\"> done</li></ul>"},
+		{"table", "| a | b |\n|:-|-:|\n| 1 | x \\| y |\n| 3 |",
+			"<table><thead><tr><th style=\"text-align: left\">a</th><th style=\"text-align: right\">b</th></tr></thead>" +
+				"<tbody><tr><td style=\"text-align: left\">1</td><td style=\"text-align: right\">x | y</td></tr>" +
+				"<tr><td style=\"text-align: left\">3</td><td style=\"text-align: right\"></td></tr></tbody></table>"},
+		{"header only table", "a | b\n--|--", "<table><thead><tr><th>a</th><th>b</th></tr></thead></table>"},
+		{"reference definitions", "[a]\n\n[A]: /url 'T'", `<p><a href="/url" rel="nofollow noopener" title="T">a</a></p>`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Convert(tc.input, Options{}).Render())
+		})
+	}
+}
+
+func TestConvertInlines(t *testing.T) {
+	cases := []struct {
+		name, input, want string
+	}{
+		{"emphasis", "*a* _b_ **c** __d__ ***e***", "<em>a</em> <em>b</em> <strong>c</strong> <strong>d</strong> <em><strong>e</strong></em>"},
+		{"nested emphasis", "*a **b** c*", "<em>a <strong>b</strong> c</em>"},
+		{"intraword underscore", "snake_case_name and _x_y_", "snake_case_name and <em>x_y</em>"},
+		{"rule of three", "**a*b**", "<strong>a*b</strong>"},
+		{"unmatched", "*a **b", "*a **b"},
+		{"strikethrough", "~~a~~ ~b~ ~~~c~~~", "<del>a</del> <del>b</del> ~~~c~~~"},
+		{"code spans", "`a` `` b`c `` `open", "<code>a</code> <code>b`c</code> `open"},
+		{"escapes", `\*a\* \[b] \q`, `*a* [b] \q`},
+		{"entities", "&amp; &copy; &#x41; &bogus;", "&amp; © A &amp;bogus;"},
+		{"hard breaks", "a  \nb\\\nc\nd", "a<br>b<br>c\nd"},
+		{"links", `[a](/x "T") [b](<with space>) [c](/p(q))`, `<a href="/x" rel="nofollow noopener" title="T">a</a> <a href="with%20space" rel="nofollow noopener">b</a> <a href="/p(q)" rel="nofollow noopener">c</a>`},
+		{"images", `![an *image*](/i.png)`, `<img alt="an image" src="/i.png">`},
+		{"link in link", "[a [b](/b) c](/a)", `[a <a href="/b" rel="nofollow noopener">b</a> c](/a)`},
+		{"emphasis and links", "*a [b*](/u) c*", `<em>a <a href="/u" rel="nofollow noopener">b*</a> c</em>`},
+		{"autolinks", "<https://x.example/?a=1&b=2> <me@x.example>", `<a href="https://x.example/?a=1&amp;b=2" rel="nofollow noopener">https://x.example/?a=1&amp;b=2</a> <a href="mailto:me@x.example" rel="nofollow noopener">me@x.example</a>`},
+		{"extended autolinks", "see www.x.example/a, or (https://x.example/b).", `see <a href="http://www.x.example/a" rel="nofollow noopener">www.x.example/a</a>, or (<a href="https://x.example/b" rel="nofollow noopener">https://x.example/b</a>).`},
+		{"raw html disabled", "<b onclick=x>hi</b>", "&lt;b onclick=x&gt;hi&lt;/b&gt;"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, "<p>"+tc.want+"</p>", Convert(tc.input, Options{}).Render())
+		})
+	}
+}
+
+func TestConvertUnsafeURLs(t *testing.T) {
+	out := Convert("[a](javascript:alert(1)) ![b](data:image/png;base64,AA) <vbscript:x>", Options{}).Render()
+	assert.Equal(t, `<p><a rel="nofollow noopener">a</a> <img alt="b"> <a rel="nofollow noopener">vbscript:x</a></p>`, out)
+
+	policy := sanitize.UGCPolicy()
+	policy.URLSchemes = append(policy.URLSchemes, "data")
+	out = Convert("![b](data:image/png;base64,AA)", Options{Policy: &policy}).Render()
+	assert.Equal(t, `<p><img alt="b" src="data:image/png;base64,AA"></p>`, out)
+}
+
+func TestConvertHTML(t *testing.T) {
+	input := strings.Join([]string{
+		`<div onclick="steal()">`,
+		`<p>kept</p>`,
+		`</div>`,
+		``,
+		`Text <b onclick=x>bold *em*</b> <a href="javascript:x">link</a> <span>unwrapped</span>`,
+		`<em>unclosed </i><br/><!-- comment -->`,
+		``,
+		`<script>alert(1)</script>`,
+	}, "\n")
+	out := Convert(input, Options{AllowHTML: true}).Render()
+	assert.Equal(t, "\n<p>kept</p>\n"+
+		`<p>Text <b>bold <em>em</em></b> <a rel="nofollow noopener">link</a> unwrapped`+"\n"+`unclosed <br></p>`, out)
+
+	// Tags are matched by name; elements closed outside their parent are
+	// dropped.
+	out = Convert("<b><b>x</b> <i>y</b></i>", Options{AllowHTML: true}).Render()
+	assert.Equal(t, "<p><b><b>x</b> y</b></p>", out)
+}
+
+func TestConvertInlineHTMLDropsScriptContent(t *testing.T) {
+	opts := Options{AllowHTML: true}
+	assert.Equal(t, "<p>hi  there</p>", Convert("hi <script>alert(1)</script> there", opts).Render())
+	assert.Equal(t, "<p>a  b <em>c</em></p>", Convert("a <style>p { color: red }</style> b <span>*c*</span>", opts).Render())
+}
+
+func TestConvertPolicySetAttrs(t *testing.T) {
+	want := `<p><a href="https://e.com" rel="nofollow noopener">x</a> ` +
+		`<a href="https://e.com" rel="nofollow noopener">https://e.com</a> ` +
+		`<a href="http://www.e.com" rel="nofollow noopener">www.e.com</a> ` +
+		`<a href="https://e.com" rel="nofollow noopener">y</a></p>`
+	assert.Equal(t, want, Convert(`[x](https://e.com) <https://e.com> www.e.com <a href="https://e.com">y</a>`, Options{AllowHTML: true}).Render())
+
+	policy := sanitize.UGCPolicy()
+	policy.SetAttrs["code"] = attrs.Props{"translate": "no"}
+	assert.Equal(t, `<p><code translate="no">x</code></p><pre><code translate="no">y`+"\n</code></pre>",
+		Convert("`x`\n\n    y", Options{Policy: &policy}).Render())
+}
+
+func TestConvertHooks(t *testing.T) {
+	opts := Options{Hooks: map[Kind]Hook{
+		Heading: func(c Construct, e *elem.Element) elem.Node {
+			e.Attrs[attrs.ID] = strings.ToLower(strings.ReplaceAll(c.Text, " ", "-"))
+			return e
+		},
+		CodeBlock: func(c Construct, e *elem.Element) elem.Node {
+			return elem.Div(attrs.Props{attrs.Class: "code " + c.Language}, elem.Text(c.Text))
+		},
+		Link: func(c Construct, e *elem.Element) elem.Node {
+			if strings.HasPrefix(c.Destination, "https://") {
+				e.Attrs[attrs.Target] = "_blank"
+			}
+			return e
+		},
+		ListItem: func(c Construct, e *elem.Element) elem.Node {
+			if c.Task {
+				e.Attrs[attrs.Class] = "task"
+			}
+			return e
+		},
+	}}
+	out := Convert("## Getting *Started*\n\n```sh\nmake\n```\n\n- [x] [docs](https://x.example) and [home](/)", opts).Render()
+	assert.Equal(t, `<h2 id="getting-started">Getting <em>Started</em></h2>`+
+		`<div class="code sh">make`+"\n</div>"+
+		`<ul><li class="task"><input checked disabled type="checkbox"> <a href="https://x.example" rel="nofollow noopener" target="_blank">docs</a> and <a href="/" rel="nofollow noopener">home</a></li></ul>`, out)
+}
@@ -0,0 +1,199 @@
+package elem
+
+import "strings"
+
+// MinifiedCSSGenerator is implemented by CSS generators that can leave out
+// optional whitespace and semicolons, such as styles.StyleManager. When
+// rendering with RenderOptions.Minify, its CSS is used instead of
+// GenerateCSS.
+type MinifiedCSSGenerator interface {
+	GenerateMinifiedCSS() string
+}
+
+// generateCSS returns the CSS of g, minified and limited to the used classes
+// if opts ask for it and g supports it.
+func generateCSS(g CSSGenerator, opts RenderOptions) string {
+	if u, ok := g.(UsedCSSGenerator); ok && opts.usedClasses != nil {
+		return u.GenerateUsedCSS(opts.usedClasses.has, opts.Minify)
+	}
+	if m, ok := g.(MinifiedCSSGenerator); ok && opts.Minify {
+		return m.GenerateMinifiedCSS()
+	}
+	return g.GenerateCSS()
+}
+
+// whitespaceElements are the elements whose text keeps its whitespace when
+// minifying, since it is either displayed as is or isn't HTML text.
+var whitespaceElements = map[string]struct{}{
+	"pre":      {},
+	"textarea": {},
+	"script":   {},
+	"style":    {},
+}
+
+// pClosers are the elements whose start tag implies the end of a preceding
+// p element.
+// See https://html.spec.whatwg.org/multipage/syntax.html#optional-tags
+var pClosers = map[string]struct{}{
+	"address":    {},
+	"article":    {},
+	"aside":      {},
+	"blockquote": {},
+	"details":    {},
+	"dialog":     {},
+	"div":        {},
+	"dl":         {},
+	"fieldset":   {},
+	"figcaption": {},
+	"figure":     {},
+	"footer":     {},
+	"form":       {},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"header":     {},
+	"hgroup":     {},
+	"hr":         {},
+	"main":       {},
+	"menu":       {},
+	"nav":        {},
+	"ol":         {},
+	"p":          {},
+	"pre":        {},
+	"search":     {},
+	"section":    {},
+	"table":      {},
+	"ul":         {},
+}
+
+// pKeepers are the parents in which the end tag of a last p element can't
+// be omitted.
+var pKeepers = map[string]struct{}{
+	"a":        {},
+	"audio":    {},
+	"del":      {},
+	"ins":      {},
+	"map":      {},
+	"noscript": {},
+	"video":    {},
+}
+
+// renderMinifiedChildren renders the children of e, leaving out the end
+// tags the HTML specification allows to omit given the element that
+// follows. Anything but an element following, including raw HTML, keeps
+// the end tag.
+func (e *Element) renderMinifiedChildren(builder *strings.Builder, opts RenderOptions) {
+	if _, ok := whitespaceElements[e.Tag]; ok {
+		opts.keepWhitespace = true
+	}
+	children := flattenChildren(e.Children)
+	for i, child := range children {
+		el, ok := child.(*Element)
+		if !ok {
+			child.RenderTo(builder, opts)
+			continue
+		}
+		var next Node
+		if i+1 < len(children) {
+			next = children[i+1]
+		}
+		el.renderTo(builder, opts, canOmitEndTag(e.Tag, el.Tag, next, opts))
+	}
+}
+
+// canOmitEndTag reports whether the end tag of an element may be left out
+// when it is followed by next, or is the last child of parent if next is
+// nil.
+func canOmitEndTag(parent, tag string, next Node, opts RenderOptions) bool {
+	if tag == "head" {
+		// RenderWithOptions injects the StyleManager's CSS before </head>.
+		return opts.StyleManager == nil && canOmitHeadEndTag(next)
+	}
+	nextTag := ""
+	if el, ok := next.(*Element); ok && el != nil {
+		nextTag = el.Tag
+	} else if next != nil {
+		return false
+	}
+
+	switch tag {
+	case "li":
+		return next == nil || nextTag == "li"
+	case "p":
+		if next == nil {
+			_, keep := pKeepers[parent]
+			return !keep && !strings.Contains(parent, "-")
+		}
+		_, closes := pClosers[nextTag]
+		return closes
+	case "td", "th":
+		return next == nil || nextTag == "td" || nextTag == "th"
+	case "tr":
+		return next == nil || nextTag == "tr"
+	case "option":
+		return next == nil || nextTag == "option" || nextTag == "optgroup"
+	case "body":
+		return next == nil
+	}
+	return false
+}
+
+// canOmitHeadEndTag reports whether </head> may be left out before next:
+// not before a comment or whitespace, which browsers would then put in the
+// head.
+func canOmitHeadEndTag(next Node) bool {
+	switch n := next.(type) {
+	case nil:
+		return true
+	case *Element:
+		return n != nil
+	case TextNode:
+		return n != "" && !isHTMLSpace(n[0])
+	}
+	return false
+}
+
+// canUnquote reports whether an attribute value can be written without
+// quotes.
+func canUnquote(value string) bool {
+	return value != "" && !strings.ContainsAny(value, " \t\n\f\r\"'=<>`")
+}
+
+// collapseWhitespace replaces each run of HTML whitespace in s with a
+// single space, which browsers render the same outside pre and textarea.
+func collapseWhitespace(s string) string {
+	// Most text has single spaces only and is returned as is.
+	i := 0
+	for ; i < len(s); i++ {
+		if isHTMLSpace(s[i]) && (s[i] != ' ' || (i+1 < len(s) && isHTMLSpace(s[i+1]))) {
+			break
+		}
+	}
+	if i == len(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	b.WriteString(s[:i])
+	space := false
+	for ; i < len(s); i++ {
+		if !isHTMLSpace(s[i]) {
+			b.WriteByte(s[i])
+			space = false
+			continue
+		}
+		if !space {
+			b.WriteByte(' ')
+		}
+		space = true
+	}
+	return b.String()
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
+}
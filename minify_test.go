@@ -0,0 +1,83 @@
+package elem
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockMinifiedStyleManager also provides minified CSS.
+type MockMinifiedStyleManager struct {
+	MockStyleManager
+}
+
+// GenerateMinifiedCSS returns a fixed CSS string for testing.
+func (m *MockMinifiedStyleManager) GenerateMinifiedCSS() string {
+	return "body{background-color:#fff}"
+}
+
+func TestMinifyAttributes(t *testing.T) {
+	e := Input(attrs.Props{
+		attrs.Type:             "text",
+		attrs.Value:            "two words",
+		attrs.Placeholder:      "",
+		"pattern":              "a=b",
+		attrs.Disabled:         "true",
+		attrs.DataAttr("json"): `'{"a": 1}'`,
+	})
+	assert.Equal(t, `<input data-json='{"a": 1}' disabled pattern="a=b" placeholder type=text value="two words">`,
+		e.RenderWithOptions(RenderOptions{Minify: true}))
+}
+
+func TestMinifyOptionalEndTags(t *testing.T) {
+	cases := []struct {
+		name string
+		node *Element
+		want string
+	}{
+		{"list items", Ul(nil, Li(nil, Text("a")), Li(nil, Text("b"))), "<ul><li>a<li>b</ul>"},
+		{"paragraphs", Div(nil, P(nil, Text("a")), P(nil, Text("b")), Span(nil, Text("c")), P(nil, Text("d"))),
+			"<div><p>a<p>b</p><span>c</span><p>d</div>"},
+		{"paragraph in link", A(nil, P(nil, Text("a"))), "<a><p>a</p></a>"},
+		{"paragraph before text", Div(nil, P(nil, Text("a")), Text("b")), "<div><p>a</p>b</div>"},
+		{"table", Table(nil, TBody(nil, Tr(nil, Td(nil, Text("1")), Td(nil, Text("2"))), Tr(nil, Td(nil, Text("3"))))),
+			"<table><tbody><tr><td>1<td>2<tr><td>3</tbody></table>"},
+		{"options", Select(nil, Option(nil, Text("a")), Option(nil, Text("b"))), "<select><option>a<option>b</select>"},
+		{"fragments and none", Ul(nil, Fragment(Li(nil, Text("a")), None()), Li(nil, Text("b"))), "<ul><li>a<li>b</ul>"},
+		{"document", Html(nil, Head(nil, Title(nil, Text("t"))), Body(nil, Text("b"))),
+			"<!DOCTYPE html><html><head><title>t</title><body>b</html>"},
+		{"head before comment", Html(nil, Head(nil), Comment("c"), Body(nil)),
+			"<!DOCTYPE html><html><head></head><!-- c --><body></html>"},
+		{"head before whitespace", Html(nil, Head(nil), Text(" \n"), Body(nil)),
+			"<!DOCTYPE html><html><head></head> <body></html>"},
+		{"head before text", Html(nil, Head(nil), Text("a")), "<!DOCTYPE html><html><head>a</html>"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.node.RenderWithOptions(RenderOptions{Minify: true}))
+		})
+	}
+}
+
+func TestMinifyWhitespace(t *testing.T) {
+	e := Div(nil,
+		Text("  a \n\t b  "),
+		Pre(nil, Text("  keep\n  this")),
+		Textarea(nil, Text("a  b")),
+		P(nil, Text("one two")),
+	)
+	assert.Equal(t, "<div> a b <pre>  keep\n  this</pre><textarea>a  b</textarea><p>one two</div>",
+		e.RenderWithOptions(RenderOptions{Minify: true}))
+	assert.Equal(t, " a b ", Text("  a \n\t b  ").RenderWithOptions(RenderOptions{Minify: true}))
+}
+
+func TestMinifyStyleManager(t *testing.T) {
+	e := Html(nil, Head(nil), Body(nil))
+
+	html := e.RenderWithOptions(RenderOptions{Minify: true, StyleManager: &MockMinifiedStyleManager{}})
+	assert.Equal(t, "<!DOCTYPE html><html><head><style>body{background-color:#fff}</style></head><body></html>", html)
+
+	html = e.RenderWithOptions(RenderOptions{Minify: true, StyleManager: &MockStyleManager{}})
+	assert.Equal(t, "<!DOCTYPE html><html><head><style>body { background-color: #fff; }</style></head><body></html>", html)
+}
@@ -0,0 +1,344 @@
+// Package sanitize turns untrusted HTML, such as user comments or CMS
+// content, into elem nodes that contain only the elements and attributes a
+// Policy allows.
+//
+// The markup is parsed rather than filtered as text, and the result is an
+// ordinary elem tree: text is escaped when rendered, attribute values are
+// re-escaped, URL attributes are checked against the allowed schemes and
+// style attributes are reduced to the allowed properties.
+package sanitize
+
+import (
+	"html"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/chasefleming/elem-go/internal/htmlparse"
+	"github.com/chasefleming/elem-go/styles"
+)
+
+// Policy describes what Sanitize keeps. Elements that aren't allowed are
+// removed but their sanitized content is kept, except for the elements in
+// which content is code or otherwise not readable text (such as script,
+// style, iframe or svg), which are removed along with their content. Script
+// and style elements and event handler attributes are removed even if the
+// policy allows them.
+type Policy struct {
+	// Elements maps each allowed tag to the attributes allowed on it.
+	Elements map[string][]string
+	// GlobalAttrs are allowed on every allowed element.
+	GlobalAttrs []string
+	// URLSchemes are the schemes allowed in URL attributes such as href and
+	// src, compared case-insensitively. URLs with any other scheme are
+	// removed, including those in the lists of srcset and ping.
+	URLSchemes []string
+	// AllowRelativeURLs allows URLs without a scheme, such as "/about" or
+	// "#top".
+	AllowRelativeURLs bool
+	// StyleProperties are the CSS properties, such as styles.TextAlign,
+	// kept in style attributes. The style attribute itself must be allowed
+	// too. Other declarations are removed, as are values that could load
+	// resources or escape the declaration.
+	StyleProperties []string
+	// SetAttrs are set on every kept element of the given tag, overriding
+	// the input, e.g. rel="nofollow noopener" on links.
+	SetAttrs map[string]attrs.Props
+}
+
+// UGCPolicy returns a policy for user-generated content such as comments:
+// text formatting, headings, lists, quotes, code, tables and images, with
+// links limited to http, https and mailto URLs and marked
+// rel="nofollow noopener". Ids, classes and styles are not allowed, so
+// content can't interfere with the page's own scripts and styles. The
+// returned policy can be modified freely.
+func UGCPolicy() Policy {
+	return Policy{
+		Elements: map[string][]string{
+			"a":          {attrs.Href},
+			"abbr":       nil,
+			"b":          nil,
+			"blockquote": {attrs.Cite},
+			"br":         nil,
+			"code":       nil,
+			"dd":         nil,
+			"del":        nil,
+			"dl":         nil,
+			"dt":         nil,
+			"em":         nil,
+			"h1":         nil,
+			"h2":         nil,
+			"h3":         nil,
+			"h4":         nil,
+			"h5":         nil,
+			"h6":         nil,
+			"hr":         nil,
+			"i":          nil,
+			"img":        {attrs.Src, attrs.Alt, attrs.Width, attrs.Height},
+			"ins":        nil,
+			"kbd":        nil,
+			"li":         nil,
+			"mark":       nil,
+			"ol":         {"start"},
+			"p":          nil,
+			"pre":        nil,
+			"q":          {attrs.Cite},
+			"s":          nil,
+			"small":      nil,
+			"strong":     nil,
+			"sub":        nil,
+			"sup":        nil,
+			"table":      nil,
+			"tbody":      nil,
+			"td":         {attrs.ColSpan, attrs.RowSpan},
+			"tfoot":      nil,
+			"th":         {attrs.ColSpan, attrs.RowSpan, attrs.Scope},
+			"thead":      nil,
+			"tr":         nil,
+			"u":          nil,
+			"ul":         nil,
+		},
+		GlobalAttrs:       []string{attrs.Title},
+		URLSchemes:        []string{"http", "https", "mailto"},
+		AllowRelativeURLs: true,
+		SetAttrs: map[string]attrs.Props{
+			"a": {attrs.Rel: "nofollow noopener"},
+		},
+	}
+}
+
+// StrictPolicy returns a policy that allows no elements at all, keeping
+// only the text of the input.
+func StrictPolicy() Policy {
+	return Policy{}
+}
+
+// urlAttrs are the attributes whose values are URLs.
+var urlAttrs = map[string]struct{}{
+	attrs.Href:   {},
+	attrs.Src:    {},
+	attrs.Cite:   {},
+	attrs.Action: {},
+	"formaction": {},
+	"poster":     {},
+	"background": {},
+	"longdesc":   {},
+	"data":       {},
+	"xlink:href": {},
+	"manifest":   {},
+	"icon":       {},
+	"codebase":   {},
+}
+
+// urlListAttrs are the attributes whose values are lists of URLs: srcset
+// lists image candidates with descriptors, ping space-separated URLs.
+var urlListAttrs = map[string]struct{}{
+	"srcset":      {},
+	"imagesrcset": {},
+	"ping":        {},
+}
+
+// dropContent are the elements removed together with their content when
+// the policy doesn't allow them.
+var dropContent = map[string]struct{}{
+	"script":   {},
+	"style":    {},
+	"template": {},
+	"iframe":   {},
+	"object":   {},
+	"embed":    {},
+	"noscript": {},
+	"noembed":  {},
+	"title":    {},
+	"textarea": {},
+	"select":   {},
+	"svg":      {},
+	"math":     {},
+}
+
+// Sanitize parses untrusted markup and returns the parts the policy allows
+// as a fragment.
+func (p Policy) Sanitize(untrusted string) *elem.Element {
+	return elem.Fragment(p.sanitizeNodes(htmlparse.Parse(untrusted))...)
+}
+
+func (p Policy) sanitizeNodes(nodes []*htmlparse.Node) []elem.Node {
+	var out []elem.Node
+	for _, n := range nodes {
+		switch n.Type {
+		case htmlparse.TextNode:
+			out = append(out, elem.Text(n.Data))
+		case htmlparse.ElementNode:
+			out = append(out, p.sanitizeElement(n)...)
+		}
+	}
+	return out
+}
+
+func (p Policy) sanitizeElement(n *htmlparse.Node) []elem.Node {
+	allowed, ok := p.Elements[n.Tag]
+	if n.Tag == "script" || n.Tag == "style" {
+		ok = false
+	}
+	if !ok {
+		if _, drop := dropContent[n.Tag]; drop {
+			return nil
+		}
+		return p.sanitizeNodes(n.Children)
+	}
+
+	props := attrs.Props{}
+	for _, a := range n.Attrs {
+		if strings.HasPrefix(a.Name, "on") || (!slices.Contains(allowed, a.Name) && !slices.Contains(p.GlobalAttrs, a.Name)) {
+			continue
+		}
+		if value, ok := p.attrValue(a); ok {
+			props[a.Name] = value
+		}
+	}
+	for name, value := range p.SetAttrs[n.Tag] {
+		props[name] = value
+	}
+	if len(props) == 0 {
+		props = nil
+	}
+	return []elem.Node{elem.NewElement(n.Tag, props, p.sanitizeNodes(n.Children)...)}
+}
+
+// attrValue returns the sanitized, escaped value of a, or false if the
+// attribute should be removed.
+func (p Policy) attrValue(a htmlparse.Attr) (string, bool) {
+	if _, isURL := urlAttrs[a.Name]; isURL {
+		u, ok := p.SanitizeURL(a.Value)
+		return html.EscapeString(u), ok
+	}
+	if _, isURLList := urlListAttrs[a.Name]; isURLList {
+		var list string
+		if a.Name == "ping" {
+			list = p.sanitizeURLs(a.Value)
+		} else {
+			list = p.sanitizeSrcset(a.Value)
+		}
+		return html.EscapeString(list), list != ""
+	}
+	if a.Name == attrs.Style {
+		style := p.sanitizeStyle(a.Value)
+		return html.EscapeString(style), style != ""
+	}
+	return htmlparse.AttrValue(a), true
+}
+
+// sanitizeURLs returns the allowed URLs of a space-separated list.
+func (p Policy) sanitizeURLs(list string) string {
+	var kept []string
+	for _, u := range strings.Fields(list) {
+		if u, ok := p.SanitizeURL(u); ok {
+			kept = append(kept, u)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// sanitizeSrcset returns the image candidates of a srcset whose URLs are
+// allowed. Each candidate is a URL, which may contain commas, followed by
+// optional descriptors such as "2x" or "480w" up to the next comma.
+func (p Policy) sanitizeSrcset(srcset string) string {
+	var kept []string
+	for i := 0; i < len(srcset); {
+		for i < len(srcset) && (isHTMLSpace(srcset[i]) || srcset[i] == ',') {
+			i++
+		}
+		start := i
+		for i < len(srcset) && !isHTMLSpace(srcset[i]) {
+			i++
+		}
+		u := srcset[start:i]
+		descriptors := ""
+		if trimmed := strings.TrimRight(u, ","); trimmed != u {
+			u = trimmed
+		} else {
+			start = i
+			for i < len(srcset) && srcset[i] != ',' {
+				i++
+			}
+			descriptors = strings.Join(strings.Fields(srcset[start:i]), " ")
+		}
+		if u == "" {
+			continue
+		}
+		if u, ok := p.SanitizeURL(u); ok {
+			kept = append(kept, strings.TrimSpace(u+" "+descriptors))
+		}
+	}
+	return strings.Join(kept, ", ")
+}
+
+func isHTMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
+}
+
+// SanitizeURL checks the scheme of u against URLSchemes and
+// AllowRelativeURLs, the way Sanitize checks URL attributes. It returns u
+// without the characters browsers ignore, unescaped, and whether it is
+// allowed.
+func (p Policy) SanitizeURL(u string) (string, bool) {
+	// Browsers ignore tabs and newlines anywhere in a URL and strip leading
+	// and trailing control characters and spaces, so "java\tscript:" is a
+	// javascript: URL.
+	u = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, u)
+	u = strings.TrimFunc(u, func(r rune) bool { return r <= ' ' })
+
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", false
+	}
+	if parsed.Scheme == "" {
+		return u, p.AllowRelativeURLs
+	}
+	for _, scheme := range p.URLSchemes {
+		if strings.EqualFold(parsed.Scheme, scheme) {
+			return u, true
+		}
+	}
+	return "", false
+}
+
+// sanitizeStyle keeps the declarations of allowed properties in style.
+func (p Policy) sanitizeStyle(style string) string {
+	if len(p.StyleProperties) == 0 {
+		return ""
+	}
+	kept := styles.Props{}
+	for _, decl := range strings.Split(style, ";") {
+		property, value, found := strings.Cut(decl, ":")
+		if !found {
+			continue
+		}
+		property = strings.ToLower(strings.TrimSpace(property))
+		value = strings.TrimSpace(value)
+		if value == "" || !slices.Contains(p.StyleProperties, property) || !safeStyleValue(value) {
+			continue
+		}
+		kept[property] = value
+	}
+	return strings.TrimSuffix(kept.ToInline(), ";")
+}
+
+// safeStyleValue rejects values that could load resources, run legacy
+// expressions or use escapes and comments to disguise either.
+func safeStyleValue(value string) bool {
+	lower := strings.ToLower(value)
+	for _, s := range []string{"url(", "expression(", "image-set(", "@import", "\\", "/*", "<", ">", "\"", "'"} {
+		if strings.Contains(lower, s) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/chasefleming/elem-go/styles"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUGCPolicy(t *testing.T) {
+	cases := []struct {
+		name, input, want string
+	}{
+		{"formatting", `<p>Hello <strong>bold</strong> and <em>em</em></p>`, `<p>Hello <strong>bold</strong> and <em>em</em></p>`},
+		{"lists and code", `<ul><li>one<li>two</ul><pre><code>x &lt; y</code></pre>`, `<ul><li>one</li><li>two</li></ul><pre><code>x &lt; y</code></pre>`},
+		{"link rel", `<a href="https://example.com" rel="author" target="_blank">x</a>`, `<a href="https://example.com" rel="nofollow noopener">x</a>`},
+		{"relative link", `<a href="/about?a=1&amp;b=2">x</a>`, `<a href="/about?a=1&amp;b=2" rel="nofollow noopener">x</a>`},
+		{"javascript url", `<a href="javascript:alert(1)">x</a>`, `<a rel="nofollow noopener">x</a>`},
+		{"obfuscated url", "<a href=\" JaVa\tScRiPt:alert(1)\">x</a>", `<a rel="nofollow noopener">x</a>`},
+		{"data url", `<img src="data:image/svg+xml;base64,AAAA" alt="a">`, `<img alt="a">`},
+		{"script dropped", `<p>a<script>alert(1)</script>b</p>`, `<p>ab</p>`},
+		{"unknown unwrapped", `<div class="x"><span>text</span></div>`, `text`},
+		{"event handlers", `<img src="a.png" onerror="alert(1)" title="t">`, `<img src="a.png" title="t">`},
+		{"ids and classes", `<p id="login" class="admin" style="color: red">x</p>`, `<p>x</p>`},
+		{"escaping", `<p title='"><script>'>1 &lt; 2 &amp; <b>3</b></p>`, `<p title="&#34;&gt;&lt;script&gt;">1 &lt; 2 &amp; <b>3</b></p>`},
+		{"comments", `a<!-- secret -->b`, `ab`},
+		{"iframe content", `<iframe src="https://evil.example">fallback</iframe>ok`, `ok`},
+	}
+	policy := UGCPolicy()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, policy.Sanitize(tc.input).Render())
+		})
+	}
+}
+
+func TestStrictPolicy(t *testing.T) {
+	assert.Equal(t, "Hello world &lt;3", StrictPolicy().Sanitize(`<h1>Hello <i>world</i></h1> &lt;3<script>x</script>`).Render())
+}
+
+func TestPolicyStyles(t *testing.T) {
+	policy := UGCPolicy()
+	policy.GlobalAttrs = append(policy.GlobalAttrs, attrs.Style)
+	policy.StyleProperties = []string{styles.TextAlign, styles.Color}
+
+	cases := map[string]string{
+		`<p style="TEXT-ALIGN: center; position: fixed; color:red">x</p>`:      `<p style="color: red; text-align: center">x</p>`,
+		`<p style="color: red; background: url(https://evil.example/x)">x</p>`: `<p style="color: red">x</p>`,
+		`<p style="color: expression(alert(1))">x</p>`:                         `<p>x</p>`,
+		`<p style="position: absolute">x</p>`:                                  `<p>x</p>`,
+	}
+	for input, want := range cases {
+		assert.Equal(t, want, policy.Sanitize(input).Render(), input)
+	}
+}
+
+func TestPolicyCustomElements(t *testing.T) {
+	policy := Policy{
+		Elements:   map[string][]string{"a": {attrs.Href}, "script": nil},
+		URLSchemes: []string{"https"},
+	}
+	assert.Equal(t, `<a>relative</a><a href="https://x.example">abs</a>`,
+		policy.Sanitize(`<a href="/rel">relative</a><a href="https://x.example">abs</a><script>alert(1)</script>`).Render(),
+		"relative URLs and scripts must stay disallowed")
+}
+
+func TestSanitizeURL(t *testing.T) {
+	policy := UGCPolicy()
+	for u, want := range map[string]bool{
+		"https://example.com":  true,
+		"MAILTO:a@example.com": true,
+		"/relative":            true,
+		"#top":                 true,
+		"javascript:alert(1)":  false,
+		"\tjava\nscript:x":     false,
+		"data:text/html,x":     false,
+	} {
+		_, ok := policy.SanitizeURL(u)
+		assert.Equal(t, want, ok, u)
+	}
+	u, _ := policy.SanitizeURL(" https://example.com/a\tb ")
+	assert.Equal(t, "https://example.com/ab", u)
+}
+
+func TestPolicyURLAttributes(t *testing.T) {
+	policy := Policy{
+		Elements: map[string][]string{
+			"a":      {attrs.Href, "ping"},
+			"img":    {attrs.Src, "srcset"},
+			"button": {"formaction"},
+			"video":  {"poster"},
+		},
+		URLSchemes: []string{"https", "data"},
+	}
+
+	cases := map[string]string{
+		`<a href="https://a.example" ping="https://t.example/1 javascript:alert(1) https://t.example/2">x</a>`: `<a href="https://a.example" ping="https://t.example/1 https://t.example/2">x</a>`,
+		`<a ping="javascript:alert(1)">x</a>`:                                                         `<a>x</a>`,
+		`<img srcset="https://i.example/a.png 1x, javascript:alert(1) 2x">`:                           `<img srcset="https://i.example/a.png 1x">`,
+		`<img srcset="data:image/png;base64,a,b 480w,https://i.example/b.png,  java&#9;script:x 2x">`: `<img srcset="data:image/png;base64,a,b 480w, https://i.example/b.png">`,
+		`<img srcset=" javascript:alert(1) ">`:                                                        `<img>`,
+		`<button formaction="javascript:alert(1)">x</button>`:                                         `<button>x</button>`,
+		`<video poster="javascript:alert(1)"></video>`:                                                `<video></video>`,
+		`<video poster="https://v.example/p.png"></video>`:                                            `<video poster="https://v.example/p.png"></video>`,
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, policy.Sanitize(in).Render(), in)
+	}
+}
@@ -0,0 +1,87 @@
+package elem
+
+import (
+	"strings"
+
+	"github.com/chasefleming/elem-go/attrs"
+)
+
+// Shadow root modes for ShadowRoot.Mode.
+const (
+	ShadowRootOpen   = "open"
+	ShadowRootClosed = "closed"
+)
+
+// ShadowRoot describes a declarative shadow root: a <template
+// shadowrootmode> that browsers attach to its parent element as a shadow tree
+// while parsing, without any JavaScript.
+// See https://developer.mozilla.org/en-US/docs/Web/HTML/Element/template#declarative_shadow_dom
+type ShadowRoot struct {
+	// Mode is ShadowRootOpen or ShadowRootClosed. It defaults to open.
+	Mode string
+	// DelegatesFocus, Clonable and Serializable set the matching
+	// shadowroot* attributes.
+	DelegatesFocus bool
+	Clonable       bool
+	Serializable   bool
+	// StyleManager's CSS is rendered in a <style> at the top of the shadow
+	// tree, where it only applies to the component's own markup. It is
+	// generated at render time, like the document-level StyleManager in
+	// RenderOptions.
+	StyleManager CSSGenerator
+	// Children make up the shadow tree. Use Slot to place the host's
+	// light-DOM children.
+	Children []Node
+}
+
+// Template renders the shadow root as a <template> element.
+func (s ShadowRoot) Template() *Element {
+	mode := s.Mode
+	if mode == "" {
+		mode = ShadowRootOpen
+	}
+	props := attrs.Props{attrs.Shadowrootmode: mode}
+	if s.DelegatesFocus {
+		props[attrs.Shadowrootdelegatesfocus] = "true"
+	}
+	if s.Clonable {
+		props[attrs.Shadowrootclonable] = "true"
+	}
+	if s.Serializable {
+		props[attrs.Shadowrootserializable] = "true"
+	}
+
+	children := s.Children
+	if s.StyleManager != nil {
+		children = append([]Node{Style(nil, styleSheetNode{s.StyleManager})}, children...)
+	}
+	return Template(props, children...)
+}
+
+// WebComponent renders the custom element name with a declarative shadow
+// root followed by its light-DOM children, which the shadow tree's <slot>
+// elements project. It panics if name is not a valid custom element name.
+func WebComponent(name string, attrs attrs.Props, shadow ShadowRoot, children ...Node) *Element {
+	return CustomElement(name, attrs, append([]Node{shadow.Template()}, children...)...)
+}
+
+// styleSheetNode renders the CSS of a CSSGenerator when it is rendered
+// rather than when the tree is built, so styles registered in between are
+// included.
+type styleSheetNode struct {
+	generator CSSGenerator
+}
+
+func (s styleSheetNode) RenderTo(builder *strings.Builder, opts RenderOptions) {
+	builder.WriteString(s.RenderWithOptions(opts))
+}
+
+func (s styleSheetNode) Render() string {
+	return s.generator.GenerateCSS()
+}
+
+func (s styleSheetNode) RenderWithOptions(opts RenderOptions) string {
+	// The classes of the document are still being collected at this point.
+	opts.usedClasses = nil
+	return generateCSS(s.generator, opts)
+}
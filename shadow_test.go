@@ -0,0 +1,54 @@
+package elem
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShadowRootTemplate(t *testing.T) {
+	root := ShadowRoot{Children: []Node{Slot(nil)}}
+	assert.Equal(t, `<template shadowrootmode="open"><slot></slot></template>`, root.Template().Render())
+
+	root = ShadowRoot{
+		Mode:           ShadowRootClosed,
+		DelegatesFocus: true,
+		Clonable:       true,
+		Serializable:   true,
+	}
+	assert.Equal(t, `<template shadowrootclonable shadowrootdelegatesfocus shadowrootmode="closed" shadowrootserializable></template>`, root.Template().Render())
+}
+
+func TestWebComponent(t *testing.T) {
+	expected := `<user-card id="ada"><template shadowrootmode="open"><style>.card { padding: 1rem; }</style>` +
+		`<div class="card"><slot name="title"></slot></div></template><h2 slot="title">Ada</h2></user-card>`
+
+	el := WebComponent("user-card", attrs.Props{attrs.ID: "ada"},
+		ShadowRoot{
+			StyleManager: &shadowCSS{css: ".card { padding: 1rem; }"},
+			Children: []Node{
+				Div(attrs.Props{attrs.Class: "card"}, Slot(attrs.Props{attrs.Name: "title"})),
+			},
+		},
+		H2(attrs.Props{"slot": "title"}, Text("Ada")),
+	)
+	assert.Equal(t, expected, el.Render())
+}
+
+func TestShadowRootStylesGeneratedAtRender(t *testing.T) {
+	css := &shadowCSS{}
+	el := WebComponent("x-box", nil, ShadowRoot{StyleManager: css})
+
+	// Styles registered after the tree is built still end up in the output.
+	css.css = "p { color: red; }"
+	assert.Equal(t, `<x-box><template shadowrootmode="open"><style>p { color: red; }</style></template></x-box>`, el.Render())
+}
+
+type shadowCSS struct {
+	css string
+}
+
+func (s *shadowCSS) GenerateCSS() string {
+	return s.css
+}
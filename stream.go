@@ -0,0 +1,198 @@
+package elem
+
+import (
+	"context"
+	"errors"
+	"html"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Suspense creates an async node for RenderStream that shows fallback, such
+// as a spinner or skeleton, until content is available. When rendered
+// otherwise, it behaves like any async node: fallback is only rendered if
+// content fails.
+func Suspense(fallback Node, content func(ctx context.Context) (Node, error)) *AsyncNode {
+	return &AsyncNode{Content: content, Fallback: fallback}
+}
+
+// StreamOptions configure RenderStream.
+type StreamOptions struct {
+	RenderOptions
+	// Workers limits how many Content functions run at once. It defaults to
+	// runtime.GOMAXPROCS(0).
+	Workers int
+	// ScriptNonce is set as the nonce of the inline scripts that swap in
+	// streamed content, for pages with a Content Security Policy.
+	ScriptNonce string
+}
+
+// swapScript defines the function that replaces the fallback between a
+// placeholder's markers with the content of its template. Placeholders can
+// be gone if they were in a fallback that has been replaced already.
+const swapScript = `function elemSwap(i){var m=document.getElementById(i),c=document.getElementById(i+"-c");` +
+	`if(!m){c.remove();return}var n=m.nextSibling;` +
+	`while(n&&!(n.nodeType===8&&n.data==="/"+i)){var x=n.nextSibling;n.remove();n=x}` +
+	`if(n)n.remove();m.replaceWith(c.content);c.remove()}`
+
+// RenderStream writes root to w without waiting for its async nodes, which
+// start out as their fallbacks. Their Content functions run concurrently,
+// and as each finishes, its content is written at the end of the document
+// with a small inline script that moves it into place. w is flushed after
+// the page shell and after each piece of content if it has a Flush method,
+// as http.ResponseWriter does, so browsers can show the page while the slow
+// parts are still loading. The end of the body is written last.
+//
+// Content that fails keeps its fallback, and the returned error joins the
+// errors in the order they occurred. If ctx is done, RenderStream stops
+// waiting, ends the document and returns ctx.Err() among the errors. The
+// CSS of a StyleManager is generated with the shell, so styles used in
+// streamed content must be registered before rendering.
+func RenderStream(ctx context.Context, w io.Writer, root Node, opts StreamOptions) error {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	s := &stream{
+		ctx:     ctx,
+		sem:     make(chan struct{}, workers),
+		results: make(chan streamResult),
+		done:    make(chan struct{}),
+	}
+	defer close(s.done)
+
+	renderOpts := opts.RenderOptions
+	renderOpts.stream = s
+	// The CSS is generated before the streamed content is rendered, so it
+	// can't be limited to the classes in use.
+	renderOpts.TreeShakeCSS = false
+	shell := root.RenderWithOptions(renderOpts)
+
+	// Content is streamed before the end of the body, as the document
+	// would otherwise continue after it.
+	end := strings.LastIndex(shell, "</body>")
+	if end < 0 {
+		end = strings.LastIndex(shell, "</html>")
+	}
+	if end < 0 {
+		end = len(shell)
+	}
+	if err := s.write(w, shell[:end]); err != nil {
+		return err
+	}
+
+	nonce := ""
+	if opts.ScriptNonce != "" {
+		nonce = ` nonce="` + html.EscapeString(opts.ScriptNonce) + `"`
+	}
+	renderOpts.Hook = nil
+	var errs []error
+	defined, canceled := false, false
+wait:
+	for s.pending > 0 {
+		select {
+		case res := <-s.results:
+			s.pending--
+			if res.err != nil && res.err == ctx.Err() {
+				canceled = true
+				continue
+			}
+			if res.err != nil {
+				errs = append(errs, res.err)
+				continue
+			}
+			var chunk strings.Builder
+			if !defined {
+				chunk.WriteString("<script" + nonce + ">" + swapScript + "</script>")
+				defined = true
+			}
+			id := placeholderID(res.id)
+			chunk.WriteString(`<template id="` + id + `-c">`)
+			res.node.RenderTo(&chunk, renderOpts)
+			chunk.WriteString(`</template><script` + nonce + `>elemSwap("` + id + `")</script>`)
+			if err := s.write(w, chunk.String()); err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+		case <-ctx.Done():
+			canceled = true
+			break wait
+		}
+	}
+	if canceled {
+		errs = append(errs, ctx.Err())
+	}
+
+	if err := s.write(w, shell[end:]); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// stream tracks the async nodes of a RenderStream call. Placeholders are
+// rendered and results handled by the goroutine calling RenderStream only.
+type stream struct {
+	ctx     context.Context
+	sem     chan struct{}
+	results chan streamResult
+	done    chan struct{}
+	next    int
+	pending int
+}
+
+type streamResult struct {
+	id   int
+	node Node
+	err  error
+}
+
+func placeholderID(id int) string {
+	return "elem-s-" + strconv.Itoa(id)
+}
+
+// placeholder writes the markers and fallback of a and starts running its
+// content.
+func (s *stream) placeholder(builder *strings.Builder, opts RenderOptions, a *AsyncNode) {
+	id := s.next
+	s.next++
+	s.pending++
+
+	builder.WriteString(`<template id="` + placeholderID(id) + `"></template>`)
+	if a.Fallback != nil {
+		a.Fallback.RenderTo(builder, opts)
+	}
+	builder.WriteString(`<!--/` + placeholderID(id) + `-->`)
+
+	go func() {
+		res := streamResult{id: id}
+		select {
+		case s.sem <- struct{}{}:
+			res.node, res.err = runAsync(s.ctx, a)
+			<-s.sem
+		case <-s.ctx.Done():
+			res.err = s.ctx.Err()
+		}
+		if res.err == nil && res.node == nil {
+			res.node = NoneNode{}
+		}
+		select {
+		case s.results <- res:
+		case <-s.done:
+		}
+	}()
+}
+
+// write writes chunk to w and flushes it.
+func (s *stream) write(w io.Writer, chunk string) error {
+	if _, err := io.WriteString(w, chunk); err != nil {
+		return err
+	}
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package elem
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkWriter records what is written between flushes.
+type chunkWriter struct {
+	buf     strings.Builder
+	chunks  []string
+	flushed chan struct{}
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+func (w *chunkWriter) Flush() {
+	w.chunks = append(w.chunks, w.buf.String())
+	w.buf.Reset()
+	if len(w.chunks) == 1 {
+		close(w.flushed)
+	}
+}
+
+func TestRenderStream(t *testing.T) {
+	w := &chunkWriter{flushed: make(chan struct{})}
+	bDone := make(chan struct{})
+	page := Html(nil, Body(nil, Main(nil,
+		Suspense(P(nil, Text("Loading")), func(ctx context.Context) (Node, error) {
+			<-bDone
+			return I(nil, Text("A")), nil
+		}),
+		Suspense(nil, func(ctx context.Context) (Node, error) {
+			<-w.flushed
+			defer close(bDone)
+			return B(nil, Text("B")), nil
+		}),
+	)))
+
+	err := RenderStream(context.Background(), w, page, StreamOptions{Workers: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`<!DOCTYPE html><html><body><main><template id="elem-s-0"></template><p>Loading</p><!--/elem-s-0-->` +
+			`<template id="elem-s-1"></template><!--/elem-s-1--></main>`,
+		`<script>` + swapScript + `</script><template id="elem-s-1-c"><b>B</b></template><script>elemSwap("elem-s-1")</script>`,
+		`<template id="elem-s-0-c"><i>A</i></template><script>elemSwap("elem-s-0")</script>`,
+		`</body></html>`,
+	}, w.chunks)
+}
+
+func TestRenderStreamNestedAndFailing(t *testing.T) {
+	errFailed := errors.New("failed")
+	inner := Suspense(Text("..."), func(ctx context.Context) (Node, error) {
+		return Text("inner"), nil
+	})
+	outer := Suspense(nil, func(ctx context.Context) (Node, error) {
+		return Div(nil, inner), nil
+	})
+	failing := Suspense(Text("unavailable"), func(ctx context.Context) (Node, error) {
+		return nil, errFailed
+	})
+	rec := httptest.NewRecorder()
+
+	err := RenderStream(context.Background(), rec, Div(attrs.Props{attrs.ID: "app"}, outer, failing), StreamOptions{
+		Workers:     1,
+		ScriptNonce: "r4nd0m",
+	})
+
+	assert.ErrorIs(t, err, errFailed)
+	assert.True(t, rec.Flushed)
+	out := rec.Body.String()
+	assert.True(t, strings.HasPrefix(out, `<div id="app"><template id="elem-s-0"></template><!--/elem-s-0-->`+
+		`<template id="elem-s-1"></template>unavailable<!--/elem-s-1--></div><script nonce="r4nd0m">`), out)
+	assert.Contains(t, out, `<template id="elem-s-0-c"><div><template id="elem-s-2"></template>...<!--/elem-s-2--></div></template>`+
+		`<script nonce="r4nd0m">elemSwap("elem-s-0")</script>`)
+	assert.True(t, strings.HasSuffix(out, `<template id="elem-s-2-c">inner</template><script nonce="r4nd0m">elemSwap("elem-s-2")</script>`), out)
+	assert.NotContains(t, out, "elem-s-1-c", "Failed content should keep its fallback")
+}
+
+func TestRenderStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	page := Body(nil, Suspense(Text("loading"), func(ctx context.Context) (Node, error) {
+		cancel()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	var out strings.Builder
+
+	err := RenderStream(ctx, &out, page, StreamOptions{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, `<body><template id="elem-s-0"></template>loading<!--/elem-s-0--></body>`, out.String())
+}
@@ -0,0 +1,122 @@
+package styles
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// atom is a single declaration of an atomic class, optionally for a
+// pseudo-class or pseudo-element and within an at-rule.
+type atom struct {
+	atRule   string
+	pseudo   string
+	property string
+	value    string
+}
+
+// SetAtomic sets whether AddStyle and AddCompositeStyle generate atomic
+// classes, as AddAtomicStyle and AddAtomicCompositeStyle do. Composite styles
+// with a Layer are never atomic.
+func (sm *StyleManager) SetAtomic(atomic bool) {
+	sm.atomic = atomic
+}
+
+// AddAtomicStyle adds a style to the manager as atomic classes, one per
+// declaration, and returns their names separated by spaces. The classes are
+// shared by all styles with the same declaration, so the CSS grows with the
+// number of distinct declarations rather than with the number of distinct
+// styles. As the order of classes on an element doesn't matter, styles
+// setting the same property should be merged with Merge rather than combined
+// on one element.
+func (sm *StyleManager) AddAtomicStyle(style Props) string {
+	var classes []string
+	sm.addAtoms(&classes, "", "", style)
+	return joinClasses(classes)
+}
+
+// AddAtomicCompositeStyle adds a composite style to the manager as atomic
+// classes, one per declaration of each pseudo-class, pseudo-element and
+// query, and returns their names separated by spaces. Queries with a
+// min-width condition are generated from the smallest width to the largest,
+// and those with a max-width condition from the largest to the smallest, so
+// that breakpoints override each other as expected. Other queries are
+// generated in the order they are first used. Layer is ignored, as atomic
+// classes are shared between styles.
+func (sm *StyleManager) AddAtomicCompositeStyle(composite CompositeStyle) string {
+	var classes []string
+	sm.addAtoms(&classes, "", "", composite.Default)
+	for _, pseudoClass := range sortedKeys(composite.PseudoClasses) {
+		sm.addAtoms(&classes, "", ensureLeadingColon(pseudoClass), composite.PseudoClasses[pseudoClass])
+	}
+	for _, pseudoElement := range sortedKeys(composite.PseudoElements) {
+		sm.addAtoms(&classes, "", ensureDoubleLeadingColon(pseudoElement), composite.PseudoElements[pseudoElement])
+	}
+	for _, mediaQuery := range sortedQueries(composite.MediaQueries, "@media") {
+		sm.addAtoms(&classes, ensureMediaPrefix(mediaQuery), "", composite.MediaQueries[mediaQuery])
+	}
+	for _, containerQuery := range sortedQueries(composite.ContainerQueries, "@container") {
+		sm.addAtoms(&classes, ensureAtRulePrefix("@container", containerQuery), "", composite.ContainerQueries[containerQuery])
+	}
+	for _, supportsQuery := range sortedKeys(composite.SupportsQueries) {
+		sm.addAtoms(&classes, ensureAtRulePrefix("@supports", supportsQuery), "", composite.SupportsQueries[supportsQuery])
+	}
+	return joinClasses(classes)
+}
+
+// addAtoms adds an atom for each declaration of style and appends their
+// class names to classes.
+func (sm *StyleManager) addAtoms(classes *[]string, atRule, pseudo string, style Props) {
+	if len(style) > 0 && atRule != "" && !slices.Contains(sm.atomAtRules, atRule) {
+		sm.atomAtRules = append(sm.atomAtRules, atRule)
+	}
+	for _, property := range sortedKeys(style) {
+		a := atom{atRule: atRule, pseudo: pseudo, property: property, value: style[property]}
+		className := fmt.Sprintf("a_%x", entityHash(a))
+		if _, exists := sm.atoms[className]; !exists {
+			sm.atoms[className] = a
+			sm.version++
+		}
+		*classes = append(*classes, className)
+	}
+}
+
+// writeAtoms writes the atomic classes, those outside at-rules first, and
+// those without a pseudo-class or pseudo-element before the others within
+// each at-rule. At-rules are ordered by orderQueries.
+func (sm *StyleManager) writeAtoms(w *cssWriter) {
+	var classNames []string
+	for _, className := range sortedKeys(sm.atoms) {
+		if w.classUsed(className) {
+			classNames = append(classNames, className)
+		}
+	}
+	write := func(atRule string) {
+		for _, pseudo := range []bool{false, true} {
+			for _, className := range classNames {
+				a := sm.atoms[className]
+				if a.atRule == atRule && (a.pseudo != "") == pseudo {
+					w.rule("."+className+a.pseudo, Props{a.property: a.value})
+				}
+			}
+		}
+	}
+
+	write("")
+	atRules := slices.Clone(sm.atomAtRules)
+	orderQueries(atRules, func(atRule string) string { return atRule })
+	for _, atRule := range atRules {
+		if !slices.ContainsFunc(classNames, func(className string) bool { return sm.atoms[className].atRule == atRule }) {
+			continue
+		}
+		w.open(atRule)
+		write(atRule)
+		w.close()
+	}
+}
+
+// joinClasses returns the sorted, unique class names separated by spaces.
+func joinClasses(classes []string) string {
+	slices.Sort(classes)
+	return strings.Join(slices.Compact(classes), " ")
+}
@@ -0,0 +1,99 @@
+package styles
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAtomicStyle(t *testing.T) {
+	sm := NewStyleManager()
+	button := sm.AddAtomicStyle(Props{Color: "white", Padding: "8px"})
+	link := sm.AddAtomicStyle(Props{Color: "white", TextDecoration: "none"})
+
+	buttonClasses, linkClasses := strings.Fields(button), strings.Fields(link)
+	assert.Len(t, buttonClasses, 2)
+	assert.Len(t, linkClasses, 2)
+	assert.Regexp(t, "^a_[0-9a-f]{10}$", buttonClasses[0])
+	assert.Len(t, sm.atoms, 3, "Declarations should be shared between styles")
+	assert.Equal(t, button, sm.AddAtomicStyle(Props{Padding: "8px", Color: "white"}), "Class lists should be deterministic")
+	assert.Equal(t, "", sm.AddAtomicStyle(Props{}))
+
+	css := sm.GenerateMinifiedCSS()
+	assert.Equal(t, 1, strings.Count(css, "{color:white}"))
+	assert.Contains(t, css, "{padding:8px}")
+	assert.Contains(t, css, "{text-decoration:none}")
+	assert.Equal(t, "btn "+button, attrs.ClassNames("btn", button))
+}
+
+func TestAddAtomicCompositeStyle(t *testing.T) {
+	sm := NewStyleManager()
+	small := sm.AddAtomicCompositeStyle(CompositeStyle{
+		Default:       Props{Color: "gray"},
+		PseudoClasses: map[string]Props{"hover": {Color: "black"}},
+		MediaQueries:  map[string]Props{"(min-width: 640px)": {Padding: "8px"}},
+	})
+	large := sm.AddAtomicCompositeStyle(CompositeStyle{
+		Default:          Props{Color: "gray"},
+		PseudoElements:   map[string]Props{"before": {Content: "''"}},
+		MediaQueries:     map[string]Props{"@media (min-width: 1024px)": {Padding: "16px"}, "(min-width: 640px)": {Padding: "8px"}},
+		ContainerQueries: map[string]Props{"(min-width: 400px)": {Display: "grid"}},
+	})
+
+	class := func(classes string, property, value string) string {
+		for _, c := range strings.Fields(classes) {
+			if a := sm.atoms[c]; a.property == property && a.value == value {
+				return c
+			}
+		}
+		t.Fatalf("no class for %s: %s in %q", property, value, classes)
+		return ""
+	}
+	gray, hover, before := class(small, Color, "gray"), class(small, Color, "black"), class(large, Content, "''")
+	p8, p16, grid := class(small, Padding, "8px"), class(large, Padding, "16px"), class(large, Display, "grid")
+	assert.Equal(t, gray, class(large, Color, "gray"))
+	assert.Equal(t, p8, class(large, Padding, "8px"))
+
+	// Atoms with a pseudo-class or pseudo-element come last, sorted by class name.
+	css := sm.GenerateMinifiedCSS()
+	assert.Equal(t, "."+gray+"{color:gray}"+
+		"."+hover+":hover{color:black}"+
+		"."+before+"::before{content:''}"+
+		"@media (min-width: 640px){."+p8+"{padding:8px}}"+
+		"@media (min-width: 1024px){."+p16+"{padding:16px}}"+
+		"@container (min-width: 400px){."+grid+"{display:grid}}",
+		css, "Queries should be generated in the order they were first used")
+}
+
+func TestAtomicQueriesOrderedByWidth(t *testing.T) {
+	sm := NewStyleManager()
+	wide := sm.AddAtomicCompositeStyle(CompositeStyle{MediaQueries: map[string]Props{"(min-width: 1024px)": {Padding: "16px"}}})
+	classes := sm.AddAtomicCompositeStyle(CompositeStyle{
+		Default: Props{Padding: "4px"},
+		MediaQueries: map[string]Props{
+			"(min-width: 1024px)": {Padding: "16px"},
+			"(min-width: 640px)":  {Padding: "8px"},
+			"(min-width: 48em)":   {Padding: "12px"},
+			"print":               {Padding: "0"},
+		},
+	})
+
+	assert.Contains(t, classes, wide)
+	css := sm.GenerateMinifiedCSS()
+	assert.Regexp(t, `^\.a_\w+\{padding:4px\}`+
+		`@media \(min-width: 640px\)\{\.a_\w+\{padding:8px\}\}`+
+		`@media \(min-width: 48em\)\{\.a_\w+\{padding:12px\}\}`+
+		`@media \(min-width: 1024px\)\{\.a_\w+\{padding:16px\}\}`+
+		`@media print\{\.a_\w+\{padding:0\}\}$`, css, "Wider breakpoints should come last even if they were used first")
+}
+
+func TestSetAtomic(t *testing.T) {
+	sm := NewStyleManager()
+	sm.SetAtomic(true)
+
+	assert.Equal(t, sm.AddAtomicStyle(Props{Color: "red", Margin: "0"}), sm.AddStyle(Props{Color: "red", Margin: "0"}))
+	assert.Len(t, strings.Fields(sm.AddCompositeStyle(CompositeStyle{Default: Props{Color: "red"}, PseudoClasses: map[string]Props{PseudoHover: {Color: "blue"}}})), 2)
+	assert.Regexp(t, "^cls_", sm.AddCompositeStyle(CompositeStyle{Default: Props{Color: "red"}, Layer: "base"}), "Layered styles should not be atomic")
+}
@@ -0,0 +1,163 @@
+package styles
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Import represents an @import rule. Layer, Supports and Media optionally
+// put the imported styles in a cascade layer or make them conditional.
+type Import struct {
+	URL      string
+	Layer    string
+	Supports string
+	Media    string
+}
+
+// FontFace represents a @font-face rule. Sources are tried in order, and
+// Props holds any other descriptors, such as "font-stretch".
+type FontFace struct {
+	Family       string
+	Sources      []FontSource
+	Weight       string
+	Style        string
+	Display      string
+	UnicodeRange string
+	Props        Props
+}
+
+// FontSource is a source of a FontFace: either a URL with an optional format
+// such as "woff2", or the name of a locally installed font.
+type FontSource struct {
+	URL    string
+	Format string
+	Local  string
+}
+
+// CustomProperty represents a @property rule registering a custom property
+// with a syntax such as "<color>" or "<length>", an initial value and
+// whether it is inherited. The name is given without the leading "--", as
+// with Var.
+type CustomProperty struct {
+	Name         string
+	Syntax       string
+	Inherits     bool
+	InitialValue string
+}
+
+// DeclareLayers declares the order of cascade layers, from lowest to highest
+// priority. Layers that are already declared keep their position.
+func (sm *StyleManager) DeclareLayers(layers ...string) {
+	for _, layer := range layers {
+		if !slices.Contains(sm.layers, layer) {
+			sm.layers = append(sm.layers, layer)
+			sm.version++
+		}
+	}
+}
+
+// AddImport adds an @import rule to the manager. Imports are generated in the
+// order they are added, after the layer order and before any other rule.
+func (sm *StyleManager) AddImport(imp Import) {
+	rule := "@import " + URL(imp.URL)
+	if imp.Layer != "" {
+		rule += " layer(" + imp.Layer + ")"
+	}
+	if imp.Supports != "" {
+		rule += " supports(" + imp.Supports + ")"
+	}
+	if imp.Media != "" {
+		rule += " " + imp.Media
+	}
+
+	if !slices.Contains(sm.imports, rule) {
+		sm.imports = append(sm.imports, rule)
+		sm.version++
+	}
+}
+
+// AddFontFace adds a @font-face rule to the manager. The font can then be
+// used by its family name.
+func (sm *StyleManager) AddFontFace(face FontFace) {
+	key := fmt.Sprintf("%x", entityHash(face))
+	if _, exists := sm.fontFaces[key]; exists {
+		return
+	}
+
+	descriptors := Merge(face.Props, Props{FontFamily: quoteString(face.Family)})
+	var sources []string
+	for _, source := range face.Sources {
+		if source.Local != "" {
+			sources = append(sources, "local("+quoteString(source.Local)+")")
+			continue
+		}
+		src := URL(source.URL)
+		if source.Format != "" {
+			src += " format(" + quoteString(source.Format) + ")"
+		}
+		sources = append(sources, src)
+	}
+	if len(sources) > 0 {
+		descriptors["src"] = strings.Join(sources, ", ")
+	}
+	for descriptor, value := range map[string]string{
+		FontWeight:      face.Weight,
+		FontStyle:       face.Style,
+		"font-display":  face.Display,
+		"unicode-range": face.UnicodeRange,
+	} {
+		if value != "" {
+			descriptors[descriptor] = value
+		}
+	}
+
+	sm.fontFaces[key] = descriptors
+	sm.version++
+}
+
+// AddProperty adds a @property rule to the manager, registering a custom
+// property. A property can only be registered once, so later registrations
+// of the same name are ignored.
+func (sm *StyleManager) AddProperty(property CustomProperty) {
+	name := "--" + strings.TrimPrefix(property.Name, "--")
+	if _, exists := sm.properties[name]; exists {
+		return
+	}
+
+	syntax := property.Syntax
+	if syntax == "" {
+		syntax = "*"
+	}
+	descriptors := Props{
+		"syntax":   "'" + syntax + "'",
+		"inherits": fmt.Sprint(property.Inherits),
+	}
+	if property.InitialValue != "" {
+		descriptors["initial-value"] = property.InitialValue
+	}
+
+	sm.properties[name] = descriptors
+	sm.version++
+}
+
+// quoteString returns s as a single-quoted CSS string. Quotes and
+// backslashes are escaped, and so are control characters and "<", so s
+// can't end the string early or close the <style> element it is in.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch {
+		case r == '\'' || r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f || r == '<':
+			fmt.Fprintf(&b, "\\%x ", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtRules(t *testing.T) {
+	sm := NewStyleManager()
+	className := sm.AddStyle(Props{Color: "red"})
+	sm.DeclareLayers("reset", "base")
+	sm.AddImport(Import{URL: "reset.css", Layer: "reset"})
+	sm.AddImport(Import{URL: "print.css", Supports: "display: grid", Media: "print"})
+	sm.AddImport(Import{URL: "reset.css", Layer: "reset"})
+	sm.DeclareLayers("base", "components")
+	sm.AddFontFace(FontFace{
+		Family: "Inter",
+		Sources: []FontSource{
+			{Local: "Inter"},
+			{URL: "/fonts/inter.woff2", Format: "woff2"},
+			{URL: "/fonts/inter.woff", Format: "woff"},
+		},
+		Weight:       "100 900",
+		Display:      "swap",
+		UnicodeRange: "U+0000-00FF",
+	})
+	sm.AddProperty(CustomProperty{Name: "angle", Syntax: "<angle>", InitialValue: "0deg"})
+	sm.AddProperty(CustomProperty{Name: "--angle", Syntax: "<length>"})
+	sm.AddProperty(CustomProperty{Name: "accent", Inherits: true})
+
+	assert.Equal(t, "@layer reset, base, components;"+
+		"@import url('reset.css') layer(reset);"+
+		"@import url('print.css') supports(display: grid) print;"+
+		"@property --accent{inherits:true;syntax:'*'}"+
+		"@property --angle{inherits:false;initial-value:0deg;syntax:'<angle>'}"+
+		"@font-face{font-display:swap;font-family:'Inter';font-weight:100 900;"+
+		"src:local('Inter'), url('/fonts/inter.woff2') format('woff2'), url('/fonts/inter.woff') format('woff');unicode-range:U+0000-00FF}"+
+		"."+className+"{color:red}",
+		sm.GenerateMinifiedCSS())
+}
+
+func TestFontFaceDeduplication(t *testing.T) {
+	sm := NewStyleManager()
+	face := FontFace{Family: "Mono", Sources: []FontSource{{URL: "mono.woff2", Format: "woff2"}}}
+	sm.AddFontFace(face)
+	sm.AddFontFace(face)
+	sm.AddFontFace(FontFace{Family: "Mono", Weight: "bold", Sources: []FontSource{{URL: "mono-bold.woff2"}}})
+
+	assert.Len(t, sm.fontFaces, 2)
+	assert.Contains(t, sm.GenerateCSS(), "@font-face { font-family: 'Mono'; font-weight: bold; src: url('mono-bold.woff2'); } ")
+}
+
+func TestFontFaceEscapesStrings(t *testing.T) {
+	sm := NewStyleManager()
+	sm.AddFontFace(FontFace{
+		Family:  `O'Neil "Sans" \`,
+		Sources: []FontSource{{Local: "x');}</style><script>"}},
+	})
+
+	assert.Equal(t, `@font-face{font-family:'O\'Neil \"Sans\" \\';src:local('x\');}\3c /style>\3c script>')}`,
+		sm.GenerateMinifiedCSS())
+}
+
+func TestCompositeStyleAtRules(t *testing.T) {
+	sm := NewStyleManager()
+	className := sm.AddCompositeStyle(CompositeStyle{
+		Default: Props{Display: "block"},
+		ContainerQueries: map[string]Props{
+			"sidebar (min-width: 400px)": {Display: "flex"},
+		},
+		SupportsQueries: map[string]Props{
+			"@supports (display: grid)": {Display: "grid"},
+		},
+		Layer: "components",
+	})
+
+	c := "." + className
+	assert.Equal(t, "@layer components{"+c+"{display:block}"+
+		"@container sidebar (min-width: 400px){"+c+"{display:flex}}"+
+		"@supports (display: grid){"+c+"{display:grid}}}",
+		sm.GenerateMinifiedCSS())
+}
@@ -0,0 +1,394 @@
+package styles
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ColorValue is a color in the sRGB color space, with its channels and alpha
+// between 0 and 1. It is parsed from CSS colors with ParseColor and can be
+// adjusted and written back in several CSS notations. String returns its
+// hex notation, so it can be used as a value in Props with fmt.
+type ColorValue struct {
+	R, G, B, A float64
+}
+
+// ParseColor parses a CSS color in hex, rgb(), rgba(), hsl() or hsla()
+// notation, or a named color such as "rebeccapurple" or "transparent".
+func ParseColor(s string) (ColorValue, error) {
+	color, ok := parseColor(strings.ToLower(strings.TrimSpace(s)))
+	if !ok {
+		return ColorValue{}, fmt.Errorf("styles: invalid color %q", s)
+	}
+	return color.clamp(), nil
+}
+
+// MustParseColor is like ParseColor but panics if s is not a valid color. It
+// simplifies declaring palettes in global variables.
+func MustParseColor(s string) ColorValue {
+	color, err := ParseColor(s)
+	if err != nil {
+		panic(err)
+	}
+	return color
+}
+
+func parseColor(s string) (ColorValue, bool) {
+	if hex, ok := strings.CutPrefix(s, "#"); ok {
+		return parseHexColor(hex)
+	}
+	if s == "transparent" {
+		return ColorValue{}, true
+	}
+	if rgb, ok := namedColors[s]; ok {
+		return rgbColor(rgb), true
+	}
+
+	name, args, ok := strings.Cut(s, "(")
+	if !ok || !strings.HasSuffix(args, ")") {
+		return ColorValue{}, false
+	}
+	fields, ok := colorArgs(strings.TrimSuffix(args, ")"))
+	if !ok {
+		return ColorValue{}, false
+	}
+	alpha := 1.0
+	if len(fields) == 4 {
+		if alpha, ok = parseColorNumber(fields[3], 1); !ok {
+			return ColorValue{}, false
+		}
+	}
+
+	switch name {
+	case "rgb", "rgba":
+		var c [3]float64
+		for i := range c {
+			if c[i], ok = parseColorNumber(fields[i], 255); !ok {
+				return ColorValue{}, false
+			}
+		}
+		return ColorValue{c[0], c[1], c[2], alpha}, true
+	case "hsl", "hsla":
+		h, ok := parseHue(fields[0])
+		if !ok {
+			return ColorValue{}, false
+		}
+		// Saturation and lightness are percentages, with or without the %.
+		s, ok := parseColorNumber(strings.TrimSuffix(fields[1], "%")+"%", 1)
+		if !ok {
+			return ColorValue{}, false
+		}
+		l, ok := parseColorNumber(strings.TrimSuffix(fields[2], "%")+"%", 1)
+		if !ok {
+			return ColorValue{}, false
+		}
+		return hslColor(h, s, l, alpha), true
+	}
+	return ColorValue{}, false
+}
+
+func parseHexColor(hex string) (ColorValue, bool) {
+	if len(hex) == 3 || len(hex) == 4 {
+		var long strings.Builder
+		for _, c := range hex {
+			long.WriteRune(c)
+			long.WriteRune(c)
+		}
+		hex = long.String()
+	}
+	if len(hex) != 6 && len(hex) != 8 {
+		return ColorValue{}, false
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return ColorValue{}, false
+	}
+	if len(hex) == 6 {
+		return rgbColor(uint32(n)), true
+	}
+	color := rgbColor(uint32(n >> 8))
+	color.A = float64(n&0xff) / 255
+	return color, true
+}
+
+// colorArgs splits the arguments of a color function, written with commas
+// or spaces and an optional "/ alpha", into three or four fields.
+func colorArgs(args string) ([]string, bool) {
+	var fields []string
+	if strings.Contains(args, ",") {
+		fields = strings.Split(args, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+	} else {
+		channels, alpha, hasAlpha := strings.Cut(args, "/")
+		fields = strings.Fields(channels)
+		if hasAlpha {
+			fields = append(fields, strings.TrimSpace(alpha))
+		}
+	}
+	return fields, len(fields) == 3 || len(fields) == 4
+}
+
+// parseColorNumber parses a number or percentage, scaling numbers down by
+// scale and percentages by 100.
+func parseColorNumber(s string, scale float64) (float64, bool) {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		s, scale = p, 100
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v / scale, true
+}
+
+// parseHue parses a hue in degrees, with or without a unit.
+func parseHue(s string) (float64, bool) {
+	unit := 1.0
+	// "grad" is checked before "rad", which it ends with.
+	for _, u := range []struct {
+		suffix  string
+		degrees float64
+	}{{"deg", 1}, {"grad", 0.9}, {"rad", 180 / math.Pi}, {"turn", 360}} {
+		if h, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, unit = h, u.degrees
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v * unit, true
+}
+
+func rgbColor(rgb uint32) ColorValue {
+	return ColorValue{
+		R: float64(rgb>>16&0xff) / 255,
+		G: float64(rgb>>8&0xff) / 255,
+		B: float64(rgb&0xff) / 255,
+		A: 1,
+	}
+}
+
+// hslColor returns the color with the given hue in degrees, and saturation,
+// lightness and alpha between 0 and 1.
+func hslColor(h, s, l, alpha float64) ColorValue {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s, l = clamp01(s), clamp01(l)
+	channel := func(n float64) float64 {
+		k := math.Mod(n+h/30, 12)
+		a := s * math.Min(l, 1-l)
+		return l - a*math.Max(-1, math.Min(math.Min(k-3, 9-k), 1))
+	}
+	return ColorValue{channel(0), channel(8), channel(4), alpha}
+}
+
+// ToHSL returns the hue of the color in degrees, and its saturation and
+// lightness between 0 and 1.
+func (c ColorValue) ToHSL() (h, s, l float64) {
+	hi := math.Max(c.R, math.Max(c.G, c.B))
+	lo := math.Min(c.R, math.Min(c.G, c.B))
+	l = (hi + lo) / 2
+	d := hi - lo
+	if d == 0 {
+		return 0, 0, l
+	}
+	s = d / (1 - math.Abs(2*l-1))
+	switch hi {
+	case c.R:
+		h = math.Mod((c.G-c.B)/d, 6)
+	case c.G:
+		h = (c.B-c.R)/d + 2
+	default:
+		h = (c.R-c.G)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h, s, l
+}
+
+// ToOKLCH returns the lightness of the color between 0 and 1, its chroma, and
+// its hue in degrees in the OKLCH color space.
+func (c ColorValue) ToOKLCH() (l, chroma, h float64) {
+	r, g, b := linearize(c.R), linearize(c.G), linearize(c.B)
+	lms := [3]float64{
+		math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b),
+		math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b),
+		math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b),
+	}
+	l = 0.2104542553*lms[0] + 0.7936177850*lms[1] - 0.0040720468*lms[2]
+	a := 1.9779984951*lms[0] - 2.4285922050*lms[1] + 0.4505937099*lms[2]
+	bb := 0.0259040371*lms[0] + 0.7827717662*lms[1] - 0.8086757660*lms[2]
+	chroma = math.Hypot(a, bb)
+	if chroma < 1e-4 {
+		return l, 0, 0
+	}
+	h = math.Atan2(bb, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return l, chroma, h
+}
+
+// Lighten returns the color with its HSL lightness increased by amount,
+// between 0 and 1.
+func (c ColorValue) Lighten(amount float64) ColorValue {
+	h, s, l := c.ToHSL()
+	return hslColor(h, s, l+amount, c.A)
+}
+
+// Darken returns the color with its HSL lightness decreased by amount,
+// between 0 and 1.
+func (c ColorValue) Darken(amount float64) ColorValue {
+	return c.Lighten(-amount)
+}
+
+// Saturate returns the color with its HSL saturation increased by amount,
+// between -1 and 1. Negative amounts desaturate the color.
+func (c ColorValue) Saturate(amount float64) ColorValue {
+	h, s, l := c.ToHSL()
+	return hslColor(h, s+amount, l, c.A)
+}
+
+// Mix returns the mix of the color with other, where weight between 0 and 1
+// is the proportion of other.
+func (c ColorValue) Mix(other ColorValue, weight float64) ColorValue {
+	weight = clamp01(weight)
+	mix := func(a, b float64) float64 { return a + (b-a)*weight }
+	return ColorValue{mix(c.R, other.R), mix(c.G, other.G), mix(c.B, other.B), mix(c.A, other.A)}
+}
+
+// WithAlpha returns the color with the given alpha, between 0 and 1.
+func (c ColorValue) WithAlpha(alpha float64) ColorValue {
+	c.A = clamp01(alpha)
+	return c
+}
+
+// Luminance returns the relative luminance of the color as defined by WCAG,
+// from 0 for black to 1 for white. Alpha is ignored.
+func (c ColorValue) Luminance() float64 {
+	return 0.2126*linearize(c.R) + 0.7152*linearize(c.G) + 0.0722*linearize(c.B)
+}
+
+// ContrastRatio returns the WCAG contrast ratio between the color and other,
+// from 1 to 21. Text should have a ratio of at least 4.5 with its
+// background, or 3 if it is large.
+func (c ColorValue) ContrastRatio(other ColorValue) float64 {
+	l1, l2 := c.Luminance(), other.Luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// ReadableOn returns the first candidate with a contrast ratio of at least
+// 4.5 with background, or the candidate with the highest ratio if none has.
+// Without candidates, it chooses between black and white.
+func ReadableOn(background ColorValue, candidates ...ColorValue) ColorValue {
+	if len(candidates) == 0 {
+		candidates = []ColorValue{{A: 1}, {R: 1, G: 1, B: 1, A: 1}}
+	}
+	best, bestRatio := candidates[0], 0.0
+	for _, candidate := range candidates {
+		ratio := candidate.ContrastRatio(background)
+		if ratio >= 4.5 {
+			return candidate
+		}
+		if ratio > bestRatio {
+			best, bestRatio = candidate, ratio
+		}
+	}
+	return best
+}
+
+// Hex returns the color in hex notation, such as "#336699", with an alpha
+// channel if the color is translucent.
+func (c ColorValue) Hex() string {
+	c = c.clamp()
+	hex := fmt.Sprintf("#%02x%02x%02x", to255(c.R), to255(c.G), to255(c.B))
+	if c.A < 1 {
+		hex += fmt.Sprintf("%02x", to255(c.A))
+	}
+	return hex
+}
+
+// RGB returns the color in rgb() notation, such as "rgb(51 102 153 / 0.5)".
+func (c ColorValue) RGB() string {
+	c = c.clamp()
+	return "rgb(" + strconv.Itoa(to255(c.R)) + " " + strconv.Itoa(to255(c.G)) + " " + strconv.Itoa(to255(c.B)) + c.alpha() + ")"
+}
+
+// HSL returns the color in hsl() notation, such as "hsl(210 50% 40%)".
+func (c ColorValue) HSL() string {
+	h, s, l := c.clamp().ToHSL()
+	return "hsl(" + formatFloat(h, 2) + " " + formatFloat(s*100, 2) + "% " + formatFloat(l*100, 2) + "%" + c.alpha() + ")"
+}
+
+// OKLCH returns the color in oklch() notation, such as
+// "oklch(62.8% 0.2577 29.23)".
+func (c ColorValue) OKLCH() string {
+	l, chroma, h := c.clamp().ToOKLCH()
+	return "oklch(" + formatFloat(l*100, 2) + "% " + formatFloat(chroma, 4) + " " + formatFloat(h, 2) + c.alpha() + ")"
+}
+
+// String returns the color in hex notation.
+func (c ColorValue) String() string {
+	return c.Hex()
+}
+
+// ColorMix returns a color-mix() value mixing the CSS colors a and b in the
+// OKLCH color space, where weight between 0 and 1 is the proportion of b.
+// Unlike Mix, the colors can be custom properties, such as the value of a
+// Token.
+func ColorMix(a, b string, weight float64) string {
+	return "color-mix(in oklch, " + a + ", " + b + " " + formatFloat(clamp01(weight)*100, 2) + "%)"
+}
+
+// alpha returns the alpha part of a CSS color function, if any.
+func (c ColorValue) alpha() string {
+	if c.A >= 1 {
+		return ""
+	}
+	return " / " + formatFloat(clamp01(c.A), 3)
+}
+
+func (c ColorValue) clamp() ColorValue {
+	return ColorValue{clamp01(c.R), clamp01(c.G), clamp01(c.B), clamp01(c.A)}
+}
+
+// linearize converts an sRGB channel to linear light.
+func linearize(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func to255(c float64) int {
+	return int(math.Round(c * 255))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// formatFloat formats v with at most prec decimals.
+func formatFloat(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,88 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		input string
+		hex   string
+	}{
+		{"#336699", "#336699"},
+		{"#369", "#336699"},
+		{"#3369", "#33336699"},
+		{"#33669980", "#33669980"},
+		{"  RebeccaPurple ", "#663399"},
+		{"transparent", "#00000000"},
+		{"rgb(51, 102, 153)", "#336699"},
+		{"rgba(51,102,153,0.5)", "#33669980"},
+		{"rgb(20% 40% 60% / 50%)", "#33669980"},
+		{"rgb(300 -5 0)", "#ff0000"},
+		{"hsl(210, 50%, 40%)", "#336699"},
+		{"hsl(210deg 50 40 / 0.5)", "#33669980"},
+		{"hsla(-150, 50%, 40%, 1)", "#336699"},
+		{"hsl(0.5turn 100% 50%)", "#00ffff"},
+		{"hsl(200grad 100% 50%)", "#00ffff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			color, err := ParseColor(tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.hex, color.Hex())
+		})
+	}
+
+	for _, input := range []string{"", "#12", "#ggg", "blurple", "rgb(1, 2)", "rgb(a b c)", "hwb(0 0% 0%)", "rgb(1 2 3"} {
+		_, err := ParseColor(input)
+		assert.Error(t, err, input)
+	}
+	assert.EqualError(t, func() (err error) { _, err = ParseColor("blurple"); return }(), `styles: invalid color "blurple"`)
+	assert.Panics(t, func() { MustParseColor("blurple") })
+}
+
+func TestColorConversions(t *testing.T) {
+	c := MustParseColor("#336699")
+
+	h, s, l := c.ToHSL()
+	assert.InDelta(t, 210, h, 0.01)
+	assert.InDelta(t, 0.5, s, 0.01)
+	assert.InDelta(t, 0.4, l, 0.01)
+	assert.Equal(t, "#336699", c.String())
+	assert.Equal(t, "rgb(51 102 153)", c.RGB())
+	assert.Equal(t, "rgb(51 102 153 / 0.5)", c.WithAlpha(0.5).RGB())
+	assert.Equal(t, "hsl(210 50% 40%)", c.HSL())
+	assert.Equal(t, "oklch(62.8% 0.2577 29.23)", MustParseColor("red").OKLCH())
+	assert.Equal(t, "oklch(100% 0 0 / 0.25)", MustParseColor("white").WithAlpha(0.25).OKLCH())
+	assert.Equal(t, "color-mix(in oklch, var(--color-primary), white 20%)", ColorMix(ColorToken("primary").Var(), "white", 0.2))
+}
+
+func TestColorAdjustments(t *testing.T) {
+	c := MustParseColor("#336699")
+
+	assert.Equal(t, "#4080bf", c.Lighten(0.1).Hex())
+	assert.Equal(t, "#264d73", c.Darken(0.1).Hex())
+	assert.Equal(t, "#ffffff", c.Lighten(1).Hex())
+	assert.Equal(t, "#1466b8", c.Saturate(0.3).Hex())
+	assert.Equal(t, "#666666", c.Saturate(-1).Hex())
+	assert.Equal(t, "#808080", MustParseColor("black").Mix(MustParseColor("white"), 0.5).Hex())
+	assert.Equal(t, "#336699", c.Mix(MustParseColor("red"), 0).Hex())
+	assert.Equal(t, "#33669900", c.WithAlpha(-1).Hex())
+}
+
+func TestContrast(t *testing.T) {
+	black, white := MustParseColor("black"), MustParseColor("white")
+
+	assert.InDelta(t, 21, black.ContrastRatio(white), 0.001)
+	assert.InDelta(t, 1, white.ContrastRatio(white), 0.001)
+	assert.InDelta(t, 4.48, MustParseColor("#777777").ContrastRatio(white), 0.01)
+	assert.Equal(t, white, ReadableOn(MustParseColor("#336699")))
+	assert.Equal(t, black, ReadableOn(MustParseColor("#ffd700")))
+
+	brand := MustParseColor("#0055ff")
+	assert.Equal(t, brand, ReadableOn(white, brand, black), "The first readable candidate should be preferred")
+	assert.Equal(t, black, ReadableOn(MustParseColor("#808080"), MustParseColor("#999999"), black, MustParseColor("#777777")))
+	assert.Equal(t, MustParseColor("#222222"), ReadableOn(black, MustParseColor("#111111"), MustParseColor("#222222")), "The candidate with the highest ratio should be chosen if none is readable")
+}
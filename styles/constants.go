@@ -40,6 +40,9 @@ const (
 	GridTemplateAreas   = "grid-template-areas"
 	GridTemplateColumns = "grid-template-columns"
 	GridTemplateRows    = "grid-template-rows"
+	Container           = "container"
+	ContainerName       = "container-name"
+	ContainerType       = "container-type"
 
 	// Box Model Properties
 	Width             = "width"
@@ -0,0 +1,179 @@
+package styles
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule represents a CSS rule with nested rules. The selector of a nested
+// rule refers to its parent's with "&", as in "&:hover", "&.is-active" or
+// "&:not(:last-child)", and is otherwise relative to it, so "> a" or "a"
+// match the children or descendants of the parent. At-rules such as
+// "@media (min-width: 768px)" apply their properties and nested rules to
+// the parent's selector.
+type Rule struct {
+	Selector string
+	Props    Props
+	Rules    []Rule
+}
+
+// AddNestedStyle adds a style with nested rules to the manager and returns a
+// class name. The nested rules are flattened to plain CSS, in the order they
+// are given:
+//
+//	className := styleMgr.AddNestedStyle(styles.Props{styles.Color: "gray"},
+//		styles.Rule{Selector: "&:hover", Props: styles.Props{styles.Color: "black"}},
+//		styles.Rule{Selector: "@media (min-width: 768px)", Rules: []styles.Rule{
+//			{Selector: "&:hover", Props: styles.Props{styles.Color: "blue"}},
+//		}},
+//	)
+func (sm *StyleManager) AddNestedStyle(style Props, rules ...Rule) string {
+	rule := Rule{Props: style, Rules: rules}
+	className := fmt.Sprintf("cls_%x", entityHash(rule))
+
+	if _, exists := sm.nestedStyles[className]; !exists {
+		sm.nestedStyles[className] = rule
+		sm.version++
+	}
+
+	return className
+}
+
+// AddGlobalRules adds rules with their own selectors to the manager, such as
+// those of an existing stylesheet read by ParseCSS. Top-level at-rules such as
+// "@media print" hold the rules they apply to in Rules. Global rules are
+// generated before the other styles, in the order they are added, and adding
+// the same rule again has no effect.
+func (sm *StyleManager) AddGlobalRules(rules ...Rule) {
+	for _, rule := range rules {
+		hash := fmt.Sprintf("%x", entityHash(rule))
+		if _, exists := sm.globalHashes[hash]; exists {
+			continue
+		}
+		sm.globalHashes[hash] = struct{}{}
+		sm.globalRules = append(sm.globalRules, rule)
+		sm.version++
+	}
+}
+
+// topLevel writes rules with their own selectors, skipping at-rules left
+// without any rule to apply to.
+func (w *cssWriter) topLevel(rules []Rule) {
+	for _, rule := range rules {
+		selector := strings.TrimSpace(rule.Selector)
+		switch {
+		case !strings.HasPrefix(selector, "@"):
+			if w.selectorUsed(selector) {
+				w.nested(selector, rule)
+			}
+		case len(rule.Rules) == 0:
+			w.rule(selector, rule.Props)
+		default:
+			inner := cssWriter{minify: w.minify, used: w.used, animations: w.animations, referenced: w.referenced}
+			inner.topLevel(rule.Rules)
+			if inner.Len() > 0 {
+				w.open(selector)
+				w.WriteString(inner.String())
+				w.close()
+			}
+		}
+	}
+}
+
+// nested writes the properties of rule for selector, followed by its nested
+// rules.
+func (w *cssWriter) nested(selector string, rule Rule) {
+	if len(rule.Props) > 0 {
+		w.rule(selector, rule.Props)
+	}
+	for _, child := range rule.Rules {
+		if strings.HasPrefix(strings.TrimSpace(child.Selector), "@") {
+			w.open(strings.TrimSpace(child.Selector))
+			w.nested(selector, Rule{Props: child.Props, Rules: child.Rules})
+			w.close()
+			continue
+		}
+		w.nested(nestSelector(selector, child.Selector), child)
+	}
+}
+
+// nestSelector resolves the selector of a nested rule against the selector
+// of its parent. Both may be lists of selectors. A selector referring to a
+// parent list more than once, such as "& + &", matches any of the parents
+// at each reference, so the list is wrapped in :is() as CSS nesting does.
+func nestSelector(parent, selector string) string {
+	parents := splitSelectorList(parent)
+	list := ":is(" + strings.Join(parents, ", ") + ")"
+	var nested []string
+	for i, p := range parents {
+		for _, s := range splitSelectorList(selector) {
+			if len(parents) > 1 {
+				if replaced, refs := replaceParentRef(s, list); refs > 1 {
+					if i == 0 {
+						nested = append(nested, replaced)
+					}
+					continue
+				}
+			}
+			if replaced, refs := replaceParentRef(s, p); refs > 0 {
+				nested = append(nested, replaced)
+			} else {
+				nested = append(nested, p+" "+s)
+			}
+		}
+	}
+	return strings.Join(nested, ", ")
+}
+
+// splitSelectorList splits a selector list at its top-level commas.
+func splitSelectorList(list string) []string {
+	var selectors []string
+	start := 0
+	for i := 0; i < len(list); i++ {
+		switch list[i] {
+		case '\\':
+			i++
+		case '"', '\'':
+			i = skipString(list, i) - 1
+		case '[':
+			i = skipBlock(list, i, '[', ']') - 1
+		case '(':
+			i = skipBlock(list, i, '(', ')') - 1
+		case ',':
+			selectors = append(selectors, strings.TrimSpace(list[start:i]))
+			start = i + 1
+		}
+	}
+	return append(selectors, strings.TrimSpace(list[start:]))
+}
+
+// replaceParentRef replaces the "&" in selector with parent, returning the
+// number of replacements.
+func replaceParentRef(selector, parent string) (string, int) {
+	var b strings.Builder
+	refs := 0
+	for i := 0; i < len(selector); {
+		switch c := selector[i]; c {
+		case '\\':
+			end := min(i+2, len(selector))
+			b.WriteString(selector[i:end])
+			i = end
+		case '"', '\'':
+			end := skipString(selector, i)
+			b.WriteString(selector[i:end])
+			i = end
+		case '[':
+			end := skipBlock(selector, i, '[', ']')
+			b.WriteString(selector[i:end])
+			i = end
+		case '&':
+			b.WriteString(parent)
+			refs++
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), refs
+}
@@ -0,0 +1,53 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddNestedStyle(t *testing.T) {
+	sm := NewStyleManager()
+	className := sm.AddNestedStyle(Props{Color: "gray"},
+		Rule{Selector: "&:hover, &.is-active", Props: Props{Color: "black"}},
+		Rule{Selector: "> a:not([href^='#'])", Props: Props{TextDecoration: "none"}},
+		Rule{Selector: "&[data-label='a&b'] span", Props: Props{FontWeight: "bold"}},
+		Rule{Selector: "@media (min-width: 768px)", Props: Props{Padding: "20px"}, Rules: []Rule{
+			{Selector: "&:hover", Props: Props{Color: "blue"}},
+			{Selector: "li", Rules: []Rule{
+				{Selector: ".dark &, &:focus-within", Props: Props{Color: "white"}},
+			}},
+		}},
+	)
+
+	assert.Regexp(t, "^cls_[0-9a-f]{10}$", className)
+	assert.Equal(t, className, sm.AddNestedStyle(Props{Color: "gray"},
+		Rule{Selector: "&:hover, &.is-active", Props: Props{Color: "black"}},
+		Rule{Selector: "> a:not([href^='#'])", Props: Props{TextDecoration: "none"}},
+		Rule{Selector: "&[data-label='a&b'] span", Props: Props{FontWeight: "bold"}},
+		Rule{Selector: "@media (min-width: 768px)", Props: Props{Padding: "20px"}, Rules: []Rule{
+			{Selector: "&:hover", Props: Props{Color: "blue"}},
+			{Selector: "li", Rules: []Rule{
+				{Selector: ".dark &, &:focus-within", Props: Props{Color: "white"}},
+			}},
+		}},
+	), "Identical styles should share their class name")
+
+	c := "." + className
+	assert.Equal(t, c+"{color:gray}"+
+		c+":hover, "+c+".is-active{color:black}"+
+		c+" > a:not([href^='#']){text-decoration:none}"+
+		c+"[data-label='a&b'] span{font-weight:bold}"+
+		"@media (min-width: 768px){"+c+"{padding:20px}"+c+":hover{color:blue}"+
+		".dark "+c+" li, "+c+" li:focus-within{color:white}}",
+		sm.GenerateMinifiedCSS())
+}
+
+func TestNestSelector(t *testing.T) {
+	assert.Equal(t, ".a .c, .a .d, .b .c, .b .d", nestSelector(".a, .b", ".c, .d"))
+	assert.Equal(t, ".a:is(.x, .y), .b:is(.x, .y)", nestSelector(".a,.b", "&:is(.x, .y)"))
+	assert.Equal(t, ".a + .a", nestSelector(".a", "& + &"))
+	assert.Equal(t, ":is(.a, .b) + :is(.a, .b)", nestSelector(".a, .b", "& + &"))
+	assert.Equal(t, ".a:hover, :is(.a, .b) + :is(.a, .b), .b:hover", nestSelector(".a, .b", "&:hover, & + &"))
+	assert.Equal(t, `.a .b\&c`, nestSelector(".a", `.b\&c`))
+}
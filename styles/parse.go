@@ -0,0 +1,625 @@
+package styles
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ParsedCSS is a stylesheet read by ParseCSS.
+type ParsedCSS struct {
+	// Layers holds the layers declared by @layer statements, in order.
+	Layers []string
+	// Imports holds the @import rules.
+	Imports []Import
+	// Properties holds the @property rules.
+	Properties []CustomProperty
+	// FontFaces holds the @font-face rules. The src descriptor is kept as is
+	// in Props rather than parsed into Sources.
+	FontFaces []FontFace
+	// Keyframes holds the @keyframes rules by animation name.
+	Keyframes map[string]Keyframes
+	// Rules holds the style rules, and the @media, @supports, @container,
+	// @layer, @scope and @starting-style rules containing them, in order.
+	// Nested rules are kept nested.
+	Rules []Rule
+	// Warnings lists the constructs that were skipped or changed.
+	Warnings []CSSWarning
+}
+
+// CSSWarning reports a construct of a stylesheet that ParseCSS skipped or
+// changed, such as an unsupported at-rule or a duplicate declaration.
+type CSSWarning struct {
+	Line    int
+	Message string
+}
+
+// String returns the warning prefixed with its line.
+func (w CSSWarning) String() string {
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
+
+// ParseCSS reads a stylesheet, such as a legacy or third-party CSS file, so
+// its rules can be added to a StyleManager with AddParsedCSS, scoped with
+// AddScopedStyles or migrated to composite styles with CompositeStyles.
+//
+// Like browsers, ParseCSS skips what it doesn't understand and carries on,
+// reporting it in Warnings: unsupported at-rules such as @page or vendor
+// prefixed @keyframes, invalid declarations, and all but the last of
+// duplicate declarations in a rule, as Props holds a single value for each
+// property. As Props has no order either, it also warns when a shorthand and
+// one of its longhands, such as margin-top and margin, would be written in
+// the opposite order by AddParsedCSS. Comments are dropped.
+func ParseCSS(css string) *ParsedCSS {
+	p := &cssParser{src: css, out: &ParsedCSS{Keyframes: make(map[string]Keyframes)}}
+	p.out.Rules = p.rules(true)
+	return p.out
+}
+
+// AddParsedCSS adds everything read by ParseCSS to the manager under its
+// original names: the layers, imports, custom properties and fonts, the
+// keyframes with AddKeyframes and the rules with AddGlobalRules. Nested rules
+// are flattened and the declarations of each rule are sorted by property,
+// which changes which declaration wins when a shorthand and one of its
+// longhands swap places; ParseCSS warns about those.
+func (sm *StyleManager) AddParsedCSS(css *ParsedCSS) {
+	sm.DeclareLayers(css.Layers...)
+	for _, imp := range css.Imports {
+		sm.AddImport(imp)
+	}
+	for _, property := range css.Properties {
+		sm.AddProperty(property)
+	}
+	for _, fontFace := range css.FontFaces {
+		sm.AddFontFace(fontFace)
+	}
+	for _, name := range sortedKeys(css.Keyframes) {
+		sm.AddKeyframes(name, css.Keyframes[name])
+	}
+	sm.AddGlobalRules(css.Rules...)
+}
+
+// simpleClassSelector matches a class selector with an optional
+// pseudo-class or pseudo-element, such as ".btn" or ".btn:nth-child(2n)".
+var simpleClassSelector = regexp.MustCompile(`^\.(-?[_a-zA-Z][\w-]*)(::?[a-zA-Z-]+(?:\([^()]*\))?)?$`)
+
+// CompositeStyles returns the rules of single classes as composite styles by
+// class name, to migrate legacy classes to StyleManager classes. Rules for
+// ".btn", ".btn:hover", ".btn::before" and ".btn" within @media, @container
+// or @supports rules go into the Default, PseudoClasses, PseudoElements,
+// MediaQueries, ContainerQueries and SupportsQueries of the "btn" composite
+// style, merged in order. The other rules are returned in order.
+//
+// As AddCompositeStyle generates new class names, the elements using the
+// classes must be updated, and rules combining them with other selectors
+// such as ".card .btn" must be rewritten.
+func (css *ParsedCSS) CompositeStyles() (map[string]CompositeStyle, []Rule) {
+	composites := make(map[string]CompositeStyle)
+	var rest []Rule
+	for _, rule := range css.Rules {
+		if !addToComposites(composites, rule) {
+			rest = append(rest, rule)
+		}
+	}
+	return composites, rest
+}
+
+// addToComposites adds a rule to the composite styles if it can be expressed
+// as composite styles, reporting whether it was.
+func addToComposites(composites map[string]CompositeStyle, rule Rule) bool {
+	atRule, query, _ := strings.Cut(strings.TrimSpace(rule.Selector), " ")
+	if !strings.HasPrefix(atRule, "@") {
+		matches, ok := classRule(rule)
+		if !ok {
+			return false
+		}
+		for _, m := range matches {
+			composite := composites[m[1]]
+			switch pseudo := m[2]; {
+			case pseudo == "":
+				composite.Default = Merge(composite.Default, rule.Props)
+			case strings.HasPrefix(pseudo, "::"):
+				composite.PseudoElements = mergeInto(composite.PseudoElements, pseudo, rule.Props)
+			default:
+				composite.PseudoClasses = mergeInto(composite.PseudoClasses, pseudo, rule.Props)
+			}
+			composites[m[1]] = composite
+		}
+		return true
+	}
+
+	if atRule != "@media" && atRule != "@container" && atRule != "@supports" || len(rule.Props) > 0 {
+		return false
+	}
+	for _, child := range rule.Rules {
+		matches, ok := classRule(child)
+		if !ok {
+			return false
+		}
+		for _, m := range matches {
+			if m[2] != "" {
+				return false
+			}
+		}
+	}
+	for _, child := range rule.Rules {
+		matches, _ := classRule(child)
+		for _, m := range matches {
+			composite := composites[m[1]]
+			switch atRule {
+			case "@media":
+				composite.MediaQueries = mergeInto(composite.MediaQueries, rule.Selector, child.Props)
+			case "@container":
+				composite.ContainerQueries = mergeInto(composite.ContainerQueries, query, child.Props)
+			case "@supports":
+				composite.SupportsQueries = mergeInto(composite.SupportsQueries, query, child.Props)
+			}
+			composites[m[1]] = composite
+		}
+	}
+	return true
+}
+
+// classRule returns the matches of simpleClassSelector for each selector of
+// a rule without nested rules, reporting whether they all match.
+func classRule(rule Rule) ([][]string, bool) {
+	if len(rule.Rules) > 0 || strings.HasPrefix(rule.Selector, "@") {
+		return nil, false
+	}
+	var matches [][]string
+	for _, selector := range splitSelectorList(rule.Selector) {
+		m := simpleClassSelector.FindStringSubmatch(selector)
+		if m == nil {
+			return nil, false
+		}
+		matches = append(matches, m)
+	}
+	return matches, true
+}
+
+func mergeInto(m map[string]Props, key string, style Props) map[string]Props {
+	if m == nil {
+		m = make(map[string]Props)
+	}
+	m[key] = Merge(m[key], style)
+	return m
+}
+
+// groupAtRules are the at-rules whose block holds rules, or declarations and
+// rules when nested in a style rule.
+var groupAtRules = map[string]bool{
+	"media":          true,
+	"supports":       true,
+	"container":      true,
+	"layer":          true,
+	"scope":          true,
+	"starting-style": true,
+}
+
+type cssParser struct {
+	src string
+	pos int
+	out *ParsedCSS
+}
+
+func (p *cssParser) warn(pos int, format string, args ...any) {
+	p.out.Warnings = append(p.out.Warnings, CSSWarning{
+		Line:    1 + strings.Count(p.src[:pos], "\n"),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// rules reads rules up to the end of the enclosing block, or of the
+// stylesheet at the top level.
+func (p *cssParser) rules(top bool) []Rule {
+	var rules []Rule
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			if !top {
+				p.warn(p.pos, "unterminated block")
+			}
+			return rules
+		}
+		switch p.src[p.pos] {
+		case '}':
+			if top {
+				p.warn(p.pos, "unexpected }")
+				p.pos++
+				continue
+			}
+			p.pos++
+			return rules
+		case '@':
+			if rule, ok := p.atRule(false); ok {
+				rules = append(rules, rule)
+			}
+			continue
+		}
+
+		start := p.pos
+		selector, stop := p.readUntil("{;}")
+		if stop != '{' {
+			p.warn(start, "expected a block after %q", selector)
+			if stop == ';' {
+				p.pos++
+			}
+			continue
+		}
+		p.pos++
+		props, nested := p.block()
+		rules = append(rules, Rule{Selector: normalizeSpace(selector), Props: props, Rules: nested})
+	}
+}
+
+// block reads the declarations and nested rules of a style rule up to the
+// end of its block.
+func (p *cssParser) block() (Props, []Rule) {
+	props := Props{}
+	var nested []Rule
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			p.warn(p.pos, "unterminated block")
+			return props, nested
+		}
+		switch p.src[p.pos] {
+		case '}':
+			p.pos++
+			return props, nested
+		case ';':
+			p.pos++
+			continue
+		case '@':
+			if rule, ok := p.atRule(true); ok {
+				nested = append(nested, rule)
+			}
+			continue
+		}
+
+		start := p.pos
+		text, stop := p.readUntil("{;}")
+		if stop == '{' {
+			p.pos++
+			childProps, childRules := p.block()
+			nested = append(nested, Rule{Selector: normalizeSpace(text), Props: childProps, Rules: childRules})
+			continue
+		}
+		if stop == ';' {
+			p.pos++
+		}
+		p.declaration(props, text, start)
+	}
+}
+
+func (p *cssParser) declaration(props Props, text string, pos int) {
+	name, value, ok := strings.Cut(text, ":")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	custom := strings.HasPrefix(name, "--")
+	if !ok || name == "" || value == "" && !custom {
+		p.warn(pos, "invalid declaration %q", text)
+		return
+	}
+	if !custom {
+		name = strings.ToLower(name)
+	}
+	if _, exists := props[name]; exists {
+		p.warn(pos, "duplicate declaration of %s, keeping the last one", name)
+	}
+	// Declarations are written sorted by property, so a shorthand and its
+	// longhands may change order, and the other one wins.
+	for _, other := range sortedKeys(props) {
+		if name < other && (isLonghand(name, other) || isLonghand(other, name)) {
+			p.warn(pos, "%s is written before %s, as declarations are sorted by property, so %s overrides it", name, other, other)
+		}
+	}
+	props[name] = value
+}
+
+// longhands lists the properties set by shorthands that aren't named after
+// them, in addition to those starting with the shorthand's name.
+var longhands = map[string][]string{
+	"columns":       {"column-width", "column-count"},
+	"font":          {"line-height"},
+	"gap":           {"row-gap", "column-gap"},
+	"inset":         {"top", "right", "bottom", "left"},
+	"place-content": {"align-content", "justify-content"},
+	"place-items":   {"align-items", "justify-items"},
+	"place-self":    {"align-self", "justify-self"},
+}
+
+// isLonghand reports whether the shorthand property sets property.
+func isLonghand(shorthand, property string) bool {
+	if strings.HasPrefix(shorthand, "--") {
+		return false
+	}
+	return strings.HasPrefix(property, shorthand+"-") || slices.Contains(longhands[shorthand], property)
+}
+
+// atRule reads an at-rule, returning it if it holds rules. Other supported
+// at-rules are added to the output.
+func (p *cssParser) atRule(inStyle bool) (Rule, bool) {
+	start := p.pos
+	p.pos++
+	end := p.pos
+	for end < len(p.src) && isIdentChar(p.src[end]) {
+		end++
+	}
+	name := strings.ToLower(p.src[p.pos:end])
+	p.pos = end
+	prelude, stop := p.readUntil("{;}")
+	prelude = normalizeSpace(prelude)
+
+	if stop != '{' {
+		if stop == ';' {
+			p.pos++
+		}
+		if inStyle {
+			p.warn(start, "unsupported nested at-rule @%s", name)
+			return Rule{}, false
+		}
+		p.statement(start, name, prelude)
+		return Rule{}, false
+	}
+	p.pos++
+
+	if groupAtRules[name] {
+		selector := "@" + name
+		if prelude != "" {
+			selector += " " + prelude
+		}
+		if inStyle {
+			props, nested := p.block()
+			return Rule{Selector: selector, Props: props, Rules: nested}, true
+		}
+		return Rule{Selector: selector, Rules: p.rules(false)}, true
+	}
+
+	switch {
+	case inStyle:
+	case name == "keyframes":
+		p.keyframes(start, unquote(prelude))
+		return Rule{}, false
+	case name == "font-face":
+		p.fontFace(start)
+		return Rule{}, false
+	case name == "property":
+		p.property(start, prelude)
+		return Rule{}, false
+	}
+	p.warn(start, "unsupported at-rule @%s", name)
+	p.skipBlock()
+	return Rule{}, false
+}
+
+// statement handles an at-rule without a block.
+func (p *cssParser) statement(start int, name, prelude string) {
+	switch name {
+	case "charset":
+		// Stylesheets are strings, so their encoding is irrelevant.
+	case "import":
+		if imp, ok := parseImport(prelude); ok {
+			p.out.Imports = append(p.out.Imports, imp)
+		} else {
+			p.warn(start, "unsupported @import %s", prelude)
+		}
+	case "layer":
+		for _, layer := range strings.Split(prelude, ",") {
+			p.out.Layers = append(p.out.Layers, strings.TrimSpace(layer))
+		}
+	default:
+		p.warn(start, "unsupported at-rule @%s", name)
+	}
+}
+
+func (p *cssParser) keyframes(start int, name string) {
+	keyframes := Keyframes{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			p.warn(p.pos, "unterminated block")
+			break
+		}
+		if p.src[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		selectorStart := p.pos
+		selector, stop := p.readUntil("{;}")
+		if stop != '{' {
+			p.warn(selectorStart, "expected a block after %q", selector)
+			if stop == ';' {
+				p.pos++
+			}
+			continue
+		}
+		p.pos++
+		props, nested := p.block()
+		if len(nested) > 0 {
+			p.warn(selectorStart, "nested rules in keyframe %q", selector)
+		}
+		key := strings.Join(splitSelectorList(strings.ToLower(selector)), ", ")
+		keyframes[key] = Merge(keyframes[key], props)
+	}
+	if name == "" {
+		p.warn(start, "@keyframes without a name")
+		return
+	}
+	p.out.Keyframes[name] = keyframes
+}
+
+func (p *cssParser) fontFace(start int) {
+	props, nested := p.block()
+	if len(nested) > 0 {
+		p.warn(start, "nested rules in @font-face")
+	}
+	fontFace := FontFace{Props: Props{}}
+	for descriptor, value := range props {
+		switch descriptor {
+		case "font-family":
+			fontFace.Family = unquote(value)
+		case "font-weight":
+			fontFace.Weight = value
+		case "font-style":
+			fontFace.Style = value
+		case "font-display":
+			fontFace.Display = value
+		case "unicode-range":
+			fontFace.UnicodeRange = value
+		default:
+			fontFace.Props[descriptor] = value
+		}
+	}
+	p.out.FontFaces = append(p.out.FontFaces, fontFace)
+}
+
+func (p *cssParser) property(start int, name string) {
+	props, nested := p.block()
+	if len(nested) > 0 {
+		p.warn(start, "nested rules in @property")
+	}
+	property := CustomProperty{
+		Name:         strings.TrimPrefix(name, "--"),
+		Syntax:       unquote(props["syntax"]),
+		Inherits:     strings.EqualFold(props["inherits"], "true"),
+		InitialValue: props["initial-value"],
+	}
+	p.out.Properties = append(p.out.Properties, property)
+}
+
+// parseImport parses the prelude of an @import rule, such as
+// `url("theme.css") layer(theme) supports(display: grid) screen`.
+func parseImport(prelude string) (Import, bool) {
+	var imp Import
+	rest := prelude
+	if url, after, ok := cutFunction(rest, "url"); ok {
+		imp.URL, rest = unquote(url), after
+	} else if strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'") {
+		end := skipString(rest, 0)
+		imp.URL, rest = unquote(rest[:end]), strings.TrimSpace(rest[end:])
+	} else {
+		return imp, false
+	}
+
+	if layer, after, ok := cutFunction(rest, "layer"); ok {
+		imp.Layer, rest = layer, after
+	} else if keyword, _, _ := strings.Cut(rest, " "); strings.EqualFold(keyword, "layer") {
+		// Import has no way to express an anonymous layer.
+		return imp, false
+	}
+	if supports, after, ok := cutFunction(rest, "supports"); ok {
+		imp.Supports, rest = supports, after
+	}
+	imp.Media = rest
+	return imp, imp.URL != ""
+}
+
+// cutFunction cuts a function such as "url(a.css)" from the start of s,
+// returning its trimmed arguments and the trimmed rest of s.
+func cutFunction(s, name string) (args, rest string, ok bool) {
+	if len(s) <= len(name) || !strings.EqualFold(s[:len(name)], name) || s[len(name)] != '(' {
+		return "", s, false
+	}
+	end := skipBlock(s, len(name), '(', ')')
+	if s[end-1] != ')' {
+		return "", s, false
+	}
+	return strings.TrimSpace(s[len(name)+1 : end-1]), strings.TrimSpace(s[end:]), true
+}
+
+// readUntil reads up to the first of the stop characters outside strings,
+// parentheses and brackets, and returns the text read without comments and
+// the stop character, or 0 at the end of the stylesheet.
+func (p *cssParser) readUntil(stops string) (string, byte) {
+	var b strings.Builder
+	depth := 0
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '/' && strings.HasPrefix(p.src[p.pos:], "/*"):
+			p.skipComment()
+			b.WriteByte(' ')
+			continue
+		case c == '\\':
+			end := min(p.pos+2, len(p.src))
+			b.WriteString(p.src[p.pos:end])
+			p.pos = end
+			continue
+		case c == '"' || c == '\'':
+			end := skipString(p.src, p.pos)
+			b.WriteString(p.src[p.pos:end])
+			p.pos = end
+			continue
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0 && strings.IndexByte(stops, c) >= 0:
+			return strings.TrimSpace(b.String()), c
+		}
+		b.WriteByte(c)
+		p.pos++
+	}
+	return strings.TrimSpace(b.String()), 0
+}
+
+// skipBlock skips the rest of a block whose opening brace has been read.
+func (p *cssParser) skipBlock() {
+	for {
+		_, stop := p.readUntil("{}")
+		switch stop {
+		case 0:
+			return
+		case '{':
+			p.pos++
+			p.skipBlock()
+		case '}':
+			p.pos++
+			return
+		}
+	}
+}
+
+// skipSpace skips whitespace, comments and the "<!--" and "-->" markers.
+func (p *cssParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch rest := p.src[p.pos:]; {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n' || rest[0] == '\r' || rest[0] == '\f':
+			p.pos++
+		case strings.HasPrefix(rest, "/*"):
+			p.skipComment()
+		case strings.HasPrefix(rest, "<!--"):
+			p.pos += 4
+		case strings.HasPrefix(rest, "-->"):
+			p.pos += 3
+		default:
+			return
+		}
+	}
+}
+
+func (p *cssParser) skipComment() {
+	end := strings.Index(p.src[p.pos+2:], "*/")
+	if end < 0 {
+		p.warn(p.pos, "unterminated comment")
+		p.pos = len(p.src)
+		return
+	}
+	p.pos += end + 4
+}
+
+// normalizeSpace replaces the runs of whitespace in s with single spaces.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// unquote removes the quotes around a CSS string.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
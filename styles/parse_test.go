@@ -0,0 +1,194 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const legacyCSS = `@charset "utf-8";
+@layer reset, base;
+@import url("reset.css") layer(reset) supports(display: grid) screen;
+@property --angle { syntax: '<angle>'; inherits: false; initial-value: 0deg; }
+@font-face {
+	font-family: "Inter";
+	src: url(/fonts/inter.woff2) format("woff2");
+	font-weight: 100 900;
+}
+
+/* Buttons { not a rule } */
+.btn, .button {
+	color: white;
+	Background: url("data:image/png;base64,iVBOR==");
+}
+.btn:hover { color: #eee }
+.btn::before { content: "{;}" }
+.card .title {
+	font-size: 1.5rem;
+	&:hover { color: red; }
+	@media (min-width: 768px) { font-size: 2rem; }
+}
+@media (min-width: 768px) {
+	.btn { padding: 10px 20px }
+}
+@keyframes spin {
+	FROM { transform: rotate(0deg) }
+	50%, 75% { opacity: .5 }
+	to { transform: rotate(360deg) }
+}
+`
+
+func TestParseCSS(t *testing.T) {
+	css := ParseCSS(legacyCSS)
+
+	assert.Empty(t, css.Warnings)
+	assert.Equal(t, []string{"reset", "base"}, css.Layers)
+	assert.Equal(t, []Import{{URL: "reset.css", Layer: "reset", Supports: "display: grid", Media: "screen"}}, css.Imports)
+	assert.Equal(t, []CustomProperty{{Name: "angle", Syntax: "<angle>", InitialValue: "0deg"}}, css.Properties)
+	assert.Equal(t, []FontFace{{
+		Family: "Inter",
+		Weight: "100 900",
+		Props:  Props{"src": `url(/fonts/inter.woff2) format("woff2")`},
+	}}, css.FontFaces)
+	assert.Equal(t, map[string]Keyframes{"spin": {
+		"from":     {Transform: "rotate(0deg)"},
+		"50%, 75%": {Opacity: ".5"},
+		"to":       {Transform: "rotate(360deg)"},
+	}}, css.Keyframes)
+	assert.Equal(t, []Rule{
+		{Selector: ".btn, .button", Props: Props{Color: "white", Background: `url("data:image/png;base64,iVBOR==")`}},
+		{Selector: ".btn:hover", Props: Props{Color: "#eee"}},
+		{Selector: ".btn::before", Props: Props{Content: `"{;}"`}},
+		{Selector: ".card .title", Props: Props{FontSize: "1.5rem"}, Rules: []Rule{
+			{Selector: "&:hover", Props: Props{Color: "red"}},
+			{Selector: "@media (min-width: 768px)", Props: Props{FontSize: "2rem"}},
+		}},
+		{Selector: "@media (min-width: 768px)", Rules: []Rule{
+			{Selector: ".btn", Props: Props{Padding: "10px 20px"}},
+		}},
+	}, css.Rules)
+}
+
+func TestParseCSSWarnings(t *testing.T) {
+	css := ParseCSS(`@namespace svg url(http://www.w3.org/2000/svg);
+@page { margin: 1cm; @top-center { content: "x" } }
+@-webkit-keyframes fade { from { opacity: 0 } }
+.a {
+	display: -webkit-box;
+	display: flex;
+	color;
+}
+}
+.b { color: red
+/* unterminated`)
+
+	var warnings []string
+	for _, w := range css.Warnings {
+		warnings = append(warnings, w.String())
+	}
+	assert.Equal(t, []string{
+		"line 1: unsupported at-rule @namespace",
+		"line 2: unsupported at-rule @page",
+		"line 3: unsupported at-rule @-webkit-keyframes",
+		"line 6: duplicate declaration of display, keeping the last one",
+		"line 7: invalid declaration \"color\"",
+		"line 9: unexpected }",
+		"line 11: unterminated comment",
+		"line 11: unterminated block",
+	}, warnings)
+	assert.Equal(t, []Rule{
+		{Selector: ".a", Props: Props{Display: "flex"}},
+		{Selector: ".b", Props: Props{Color: "red"}},
+	}, css.Rules)
+	assert.Empty(t, css.Keyframes)
+}
+
+func TestParseCSSShorthandOrderWarnings(t *testing.T) {
+	css := ParseCSS(`.a {
+	margin-top: 5px;
+	margin: 0;
+	padding: 0;
+	padding-left: 1px;
+	row-gap: 1px;
+	gap: 0;
+	columns: 2;
+	column-width: 10em;
+	--x: 1;
+	--x-y: 2;
+}`)
+
+	var warnings []string
+	for _, w := range css.Warnings {
+		warnings = append(warnings, w.String())
+	}
+	assert.Equal(t, []string{
+		"line 3: margin is written before margin-top, as declarations are sorted by property, so margin-top overrides it",
+		"line 7: gap is written before row-gap, as declarations are sorted by property, so row-gap overrides it",
+		"line 9: column-width is written before columns, as declarations are sorted by property, so columns overrides it",
+	}, warnings)
+}
+
+func TestParseCSSRoundTrip(t *testing.T) {
+	sm := NewStyleManager()
+	sm.AddParsedCSS(ParseCSS(legacyCSS))
+
+	expected := "@layer reset, base;" +
+		"@import url('reset.css') layer(reset) supports(display: grid) screen;" +
+		"@property --angle{inherits:false;initial-value:0deg;syntax:'<angle>'}" +
+		"@font-face{font-family:'Inter';font-weight:100 900;src:url(/fonts/inter.woff2) format(\"woff2\")}" +
+		`.btn, .button{background:url("data:image/png;base64,iVBOR==");color:white}` +
+		".btn:hover{color:#eee}" +
+		`.btn::before{content:"{;}"}` +
+		".card .title{font-size:1.5rem}" +
+		".card .title:hover{color:red}" +
+		"@media (min-width: 768px){.card .title{font-size:2rem}}" +
+		"@media (min-width: 768px){.btn{padding:10px 20px}}" +
+		"@keyframes spin{50%, 75%{opacity:.5}from{transform:rotate(0deg)}to{transform:rotate(360deg)}}"
+	assert.Equal(t, expected, sm.GenerateMinifiedCSS())
+
+	again := NewStyleManager()
+	reparsed := ParseCSS(sm.GenerateCSS())
+	assert.Empty(t, reparsed.Warnings)
+	again.AddParsedCSS(reparsed)
+	assert.Equal(t, expected, again.GenerateMinifiedCSS(), "Generated CSS should parse back to the same rules")
+}
+
+func TestParsedCSSCompositeStyles(t *testing.T) {
+	composites, rest := ParseCSS(legacyCSS + `
+@supports (display: grid) { .grid { display: grid } }
+@media print { .btn:hover { color: black } }
+`).CompositeStyles()
+
+	assert.Equal(t, map[string]CompositeStyle{
+		"btn": {
+			Default:        Props{Color: "white", Background: `url("data:image/png;base64,iVBOR==")`},
+			PseudoClasses:  map[string]Props{":hover": {Color: "#eee"}},
+			PseudoElements: map[string]Props{"::before": {Content: `"{;}"`}},
+			MediaQueries:   map[string]Props{"@media (min-width: 768px)": {Padding: "10px 20px"}},
+		},
+		"button": {
+			Default: Props{Color: "white", Background: `url("data:image/png;base64,iVBOR==")`},
+		},
+		"grid": {
+			SupportsQueries: map[string]Props{"(display: grid)": {Display: "grid"}},
+		},
+	}, composites)
+	assert.Len(t, rest, 2)
+	assert.Equal(t, ".card .title", rest[0].Selector)
+	assert.Equal(t, "@media print", rest[1].Selector)
+}
+
+func TestAddGlobalRulesTreeShaking(t *testing.T) {
+	sm := NewStyleManager()
+	sm.AddGlobalRules(
+		Rule{Selector: "body", Props: Props{Margin: "0"}},
+		Rule{Selector: ".legacy", Props: Props{Animation: "spin 1s"}},
+		Rule{Selector: "@media print", Rules: []Rule{{Selector: ".legacy", Props: Props{Display: "none"}}}},
+	)
+	sm.AddGlobalRules(Rule{Selector: "body", Props: Props{Margin: "0"}})
+	sm.AddKeyframes("spin", Keyframes{"to": {Transform: "rotate(360deg)"}})
+
+	assert.Equal(t, "body{margin:0}", sm.GenerateUsedCSS(func(string) bool { return false }, true))
+	assert.Equal(t, "body{margin:0}.legacy{animation:spin 1s}@media print{.legacy{display:none}}"+
+		"@keyframes spin{to{transform:rotate(360deg)}}", sm.GenerateUsedCSS(func(string) bool { return true }, true))
+}
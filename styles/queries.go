@@ -0,0 +1,71 @@
+package styles
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// widthFeature matches the first min-width or max-width condition of a
+// query, such as "(min-width: 640px)".
+var widthFeature = regexp.MustCompile(`\b(min|max)-width\s*:\s*([0-9]*\.?[0-9]+)(px|r?em)\b`)
+
+// orderQueries sorts at-rules so that breakpoints override each other as
+// expected: at-rules of the same kind with a min-width condition go from
+// the smallest width to the largest, and those with a max-width condition
+// from the largest to the smallest. Other at-rules keep their order, and
+// each group of breakpoints stays where its first at-rule was. prelude
+// returns the at-rule of an element of queries, such as "@media (...)".
+func orderQueries(queries []string, prelude func(string) string) {
+	type queryOrder struct {
+		group int
+		width float64
+	}
+	groups := make(map[string]int)
+	orders := make(map[string]queryOrder, len(queries))
+	for i, query := range queries {
+		atRule := prelude(query)
+		match := widthFeature.FindStringSubmatch(atRule)
+		if match == nil {
+			orders[query] = queryOrder{group: i}
+			continue
+		}
+		group := strings.Fields(atRule)[0] + " " + match[1]
+		if _, ok := groups[group]; !ok {
+			groups[group] = i
+		}
+		width, _ := strconv.ParseFloat(match[2], 64)
+		if match[3] != "px" {
+			// Relative units in media queries are based on the initial
+			// font size, usually 16px.
+			width *= 16
+		}
+		if match[1] == "max" {
+			width = -width
+		}
+		orders[query] = queryOrder{group: groups[group], width: width}
+	}
+	slices.SortStableFunc(queries, func(a, b string) int {
+		oa, ob := orders[a], orders[b]
+		if oa.group != ob.group {
+			return oa.group - ob.group
+		}
+		switch {
+		case oa.width < ob.width:
+			return -1
+		case oa.width > ob.width:
+			return 1
+		}
+		return 0
+	})
+}
+
+// sortedQueries returns the queries of a composite style in the order they
+// are generated, see orderQueries. atRule is the at-rule they belong to,
+// such as "@media".
+func sortedQueries(queries map[string]Props, atRule string) []string {
+	keys := sortedKeys(queries)
+	orderQueries(keys, func(query string) string { return ensureAtRulePrefix(atRule, query) })
+	return keys
+}
@@ -0,0 +1,128 @@
+package styles
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AddScopedStyles adds the rules of a component's stylesheet, mapping
+// selectors such as ".card", ".card h2" or ".card > .actions" to their
+// styles. Class names in the selectors are local to the stylesheet: each is
+// rewritten to a unique name made of the local name and a hash of the
+// stylesheet, like CSS modules do, so components can use the same local
+// names without their styles clashing. It returns a map from the local
+// class names to the generated ones:
+//
+//	classes := styleMgr.AddScopedStyles(styles.StyleSheet{
+//		".card":            {styles.Padding: "1rem"},
+//		".card h2":         {styles.FontSize: "1.25rem"},
+//		".card > .actions": {styles.Display: "flex"},
+//	})
+//	elem.Div(attrs.Props{attrs.Class: classes["card"]}, ...)
+//
+// Classes wrapped in :global(), as in ".card :global(.active)", are kept as
+// they are. Class names in strings and attribute selectors are not
+// rewritten. Rules are generated in order of their selectors, so rules
+// matching the same element should differ in specificity rather than order.
+func (sm *StyleManager) AddScopedStyles(sheet StyleSheet) map[string]string {
+	suffix := fmt.Sprintf("_%x", entityHash(sheet))
+	classes := make(map[string]string)
+	rename := func(local string) string {
+		classes[local] = local + suffix
+		return local + suffix
+	}
+
+	scoped := make(StyleSheet, len(sheet))
+	for selector, style := range sheet {
+		scoped[scopeSelector(selector, rename)] = style
+	}
+	if _, exists := sm.scopedStyles[suffix]; !exists {
+		sm.scopedStyles[suffix] = scoped
+		sm.version++
+	}
+
+	return classes
+}
+
+// scopeSelector replaces the class names in selector with the names returned
+// by rename, except in strings, attribute selectors and :global().
+func scopeSelector(selector string, rename func(string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(selector); {
+		c := selector[i]
+		switch {
+		case c == '\\' && i+1 < len(selector):
+			b.WriteString(selector[i : i+2])
+			i += 2
+		case c == '"' || c == '\'':
+			end := skipString(selector, i)
+			b.WriteString(selector[i:end])
+			i = end
+		case c == '[':
+			end := skipBlock(selector, i, '[', ']')
+			b.WriteString(selector[i:end])
+			i = end
+		case strings.HasPrefix(selector[i:], ":global("):
+			start := i + len(":global")
+			end := skipBlock(selector, start, '(', ')')
+			b.WriteString(strings.TrimSuffix(selector[start+1:end], ")"))
+			i = end
+		case c == '.' && i+1 < len(selector) && isIdentStart(selector[i+1]):
+			end := i + 1
+			for end < len(selector) && isIdentChar(selector[end]) {
+				end++
+			}
+			b.WriteString(".")
+			b.WriteString(rename(selector[i+1 : end]))
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// skipString returns the index after the string starting at s[start].
+func skipString(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// skipBlock returns the index after the block starting with the left
+// character at s[start], skipping nested blocks and strings.
+func skipBlock(s string, start int, left, right byte) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"', '\'':
+			i = skipString(s, i) - 1
+		case left:
+			depth++
+		case right:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+func isIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-' || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
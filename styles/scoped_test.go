@@ -0,0 +1,60 @@
+package styles
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddScopedStyles(t *testing.T) {
+	sm := NewStyleManager()
+	sheet := StyleSheet{
+		".card":                      {Padding: "1rem"},
+		".card h2":                   {FontSize: "1.25rem"},
+		".card > .actions":           {Display: "flex"},
+		".card:hover, .card.active":  {BorderColor: "blue"},
+		".card :global(.icon)":       {Width: "1em"},
+		`.card a[href$=".pdf"]`:      {Color: "red"},
+		".card-title::before":        {Content: `".x"`},
+		`.card .actions\.more`:       {Margin: "0"},
+		".card :global(.is-open) h2": {Margin: "0"},
+	}
+
+	classes := sm.AddScopedStyles(sheet)
+
+	suffix := strings.TrimPrefix(classes["card"], "card")
+	assert.Regexp(t, "^_[0-9a-f]{10}$", suffix)
+	assert.Equal(t, map[string]string{
+		"card":       "card" + suffix,
+		"actions":    "actions" + suffix,
+		"active":     "active" + suffix,
+		"card-title": "card-title" + suffix,
+	}, classes)
+
+	css := sm.GenerateCSS()
+	card, actions := "."+classes["card"], "."+classes["actions"]
+	assert.Contains(t, css, card+" { padding: 1rem; } ")
+	assert.Contains(t, css, card+" h2 { font-size: 1.25rem; } ")
+	assert.Contains(t, css, card+" > "+actions+" { display: flex; } ")
+	assert.Contains(t, css, card+":hover, "+card+"."+classes["active"]+" { border-color: blue; } ")
+	assert.Contains(t, css, card+" .icon { width: 1em; } ")
+	assert.Contains(t, css, card+` a[href$=".pdf"] { color: red; } `)
+	assert.Contains(t, css, "."+classes["card-title"]+`::before { content: ".x"; } `)
+	assert.Contains(t, css, card+` `+actions+`\.more { margin: 0; } `)
+	assert.Contains(t, css, card+" .is-open h2 { margin: 0; } ")
+}
+
+func TestAddScopedStylesIsolation(t *testing.T) {
+	sm := NewStyleManager()
+	button := StyleSheet{".root": {Color: "red"}}
+	link := StyleSheet{".root": {Color: "blue"}}
+
+	buttonClasses := sm.AddScopedStyles(button)
+	linkClasses := sm.AddScopedStyles(link)
+
+	assert.NotEqual(t, buttonClasses["root"], linkClasses["root"], "The same local name should be scoped to each stylesheet")
+	assert.Equal(t, buttonClasses, sm.AddScopedStyles(StyleSheet{".root": {Color: "red"}}), "Identical stylesheets should share their class names")
+	assert.Len(t, sm.scopedStyles, 2)
+	assert.Equal(t, "."+buttonClasses["root"]+"{color:red}", strings.Replace(sm.GenerateMinifiedCSS(), "."+linkClasses["root"]+"{color:blue}", "", 1))
+}
@@ -3,30 +3,49 @@ package styles
 import (
 	"crypto/sha1"
 	"fmt"
-	"sort"
+	"slices"
 	"strings"
 )
 
 // Keyframes represents CSS keyframes for an animation.
 type Keyframes map[string]Props
 
-// CompositeStyle represents a collection of styles.
+// CompositeStyle represents a collection of styles. If Layer is set, the
+// rules are put in that cascade layer.
 type CompositeStyle struct {
-	Default       Props
-	PseudoClasses map[string]Props
-	PseudoElements map[string]Props
-	MediaQueries  map[string]Props
+	Default          Props
+	PseudoClasses    map[string]Props
+	PseudoElements   map[string]Props
+	MediaQueries     map[string]Props
+	ContainerQueries map[string]Props
+	SupportsQueries  map[string]Props
+	Layer            string
 }
 
 // StyleSheet represents a collection of styles mapped to class names.
 type StyleSheet map[string]Props
 
-// StyleManager manages styles and generates CSS classes.
+// StyleManager manages styles and generates CSS classes. It isn't safe for
+// concurrent use, so styles shared by concurrent renders should be added
+// before they start.
 type StyleManager struct {
 	styles          StyleSheet
 	compositeStyles map[string]CompositeStyle
 	animations      map[string]Keyframes
 	mediaQueries    map[string]Props
+	layers          []string
+	imports         []string
+	fontFaces       map[string]Props
+	properties      map[string]Props
+	themes          map[string]Theme
+	atoms           map[string]atom
+	atomAtRules     []string
+	atomic          bool
+	nestedStyles    map[string]Rule
+	globalRules     []Rule
+	globalHashes    map[string]struct{}
+	scopedStyles    map[string]StyleSheet
+	version         uint64
 }
 
 // NewStyleManager creates a new instance of StyleManager.
@@ -36,15 +55,27 @@ func NewStyleManager() *StyleManager {
 		animations:      make(map[string]Keyframes),
 		compositeStyles: make(map[string]CompositeStyle),
 		mediaQueries:    make(map[string]Props),
+		fontFaces:       make(map[string]Props),
+		properties:      make(map[string]Props),
+		themes:          make(map[string]Theme),
+		atoms:           make(map[string]atom),
+		nestedStyles:    make(map[string]Rule),
+		globalHashes:    make(map[string]struct{}),
+		scopedStyles:    make(map[string]StyleSheet),
 	}
 }
 
-// AddStyle adds a new style to the manager and returns a class name.
+// AddStyle adds a new style to the manager and returns a class name, or the
+// names of its atomic classes if the manager is atomic.
 func (sm *StyleManager) AddStyle(style Props) string {
+	if sm.atomic {
+		return sm.AddAtomicStyle(style)
+	}
 	className := fmt.Sprintf("cls_%x", entityHash(style))
 
 	if _, exists := sm.styles[className]; !exists {
 		sm.styles[className] = style
+		sm.version++
 	}
 
 	return className
@@ -56,91 +87,234 @@ func (sm *StyleManager) AddAnimation(keyframes Keyframes) string {
 
 	if _, exists := sm.animations[animationName]; !exists {
 		sm.animations[animationName] = keyframes
+		sm.version++
 	}
 
 	return animationName
 }
 
+// AddKeyframes adds a keyframes animation with the given name, such as one
+// from an existing stylesheet. Unlike with AddAnimation, the name doesn't
+// depend on the keyframes, so if an animation of that name exists already, it
+// is kept instead.
+func (sm *StyleManager) AddKeyframes(name string, keyframes Keyframes) {
+	if _, exists := sm.animations[name]; !exists {
+		sm.animations[name] = keyframes
+		sm.version++
+	}
+}
+
+// AddCompositeStyle adds a new composite style to the manager and returns a
+// class name, or the names of its atomic classes if the manager is atomic.
 func (sm *StyleManager) AddCompositeStyle(composite CompositeStyle) string {
+	if sm.atomic && composite.Layer == "" {
+		return sm.AddAtomicCompositeStyle(composite)
+	}
 	className := fmt.Sprintf("cls_%x", entityHash(composite))
 
 	if _, exists := sm.compositeStyles[className]; !exists {
 		sm.compositeStyles[className] = composite
+		sm.version++
 	}
 
 	return className
 }
 
+// Version returns a number that changes whenever styles are added to the
+// manager, so that the generated CSS can be cached until then. Adding
+// styles that already exist doesn't change it.
+func (sm *StyleManager) Version() uint64 {
+	return sm.version
+}
+
 // GenerateCSS generates the CSS string for all styles managed by StyleManager.
+// Rules are ordered by class and animation name, so the output only changes
+// when the styles do.
 func (sm *StyleManager) GenerateCSS() string {
-	var builder strings.Builder
+	w := cssWriter{}
+	sm.writeCSS(&w)
+	return w.String()
+}
+
+// GenerateMinifiedCSS generates the same CSS as GenerateCSS without optional
+// whitespace and semicolons.
+func (sm *StyleManager) GenerateMinifiedCSS() string {
+	w := cssWriter{minify: true}
+	sm.writeCSS(&w)
+	return w.String()
+}
+
+func (sm *StyleManager) writeCSS(w *cssWriter) {
+	// The layer order and imports must come before any other rule.
+	if len(sm.layers) > 0 {
+		w.statement("@layer " + strings.Join(sm.layers, ", "))
+	}
+	for _, imp := range sm.imports {
+		w.statement(imp)
+	}
+
+	for _, name := range sortedKeys(sm.properties) {
+		w.rule("@property "+name, sm.properties[name])
+	}
 
-	for className, style := range sm.styles {
-		keys := sortedKeys(style)
-		builder.WriteString(fmt.Sprintf(".%s { ", className))
-		for _, prop := range keys {
-			builder.WriteString(fmt.Sprintf("%s: %s; ", prop, style[prop]))
+	for _, key := range sortedKeys(sm.fontFaces) {
+		w.rule("@font-face", sm.fontFaces[key])
+	}
+
+	for _, key := range sortedKeys(sm.themes) {
+		sm.themes[key].writeCSS(w)
+	}
+
+	w.topLevel(sm.globalRules)
+
+	for _, className := range sortedKeys(sm.styles) {
+		if w.classUsed(className) {
+			w.rule("."+className, sm.styles[className])
 		}
-		builder.WriteString("} ")
 	}
 
-	for animationName, keyframes := range sm.animations {
-		builder.WriteString(fmt.Sprintf("@keyframes %s { ", animationName))
-		keys := sortedKeys(keyframes)
-		for _, key := range keys {
-			style := keyframes[key]
-			builder.WriteString(fmt.Sprintf("%s { ", key))
-			for prop, value := range style {
-				builder.WriteString(fmt.Sprintf("%s: %s; ", prop, value))
-			}
-			builder.WriteString("} ")
+	for _, animationName := range sortedKeys(sm.animations) {
+		if !w.animationUsed(animationName) {
+			continue
 		}
-		builder.WriteString("} ")
+		keyframes := sm.animations[animationName]
+		w.open("@keyframes " + animationName)
+		for _, key := range sortedKeys(keyframes) {
+			w.rule(key, keyframes[key])
+		}
+		w.close()
 	}
 
-	for className, composite := range sm.compositeStyles {
-		keys := sortedKeys(composite.Default)
-		builder.WriteString(fmt.Sprintf(".%s { ", className))
-		for _, prop := range keys {
-			builder.WriteString(fmt.Sprintf("%s: %s; ", prop, composite.Default[prop]))
+	for _, className := range sortedKeys(sm.compositeStyles) {
+		if !w.classUsed(className) {
+			continue
+		}
+		composite := sm.compositeStyles[className]
+		if composite.Layer != "" {
+			w.open("@layer " + composite.Layer)
 		}
-		builder.WriteString("} ")
+		w.rule("."+className, composite.Default)
 
-		for pseudoClass, style := range composite.PseudoClasses {
+		for _, pseudoClass := range sortedKeys(composite.PseudoClasses) {
 			// Ensure pseudoClass starts with a colon
-			formattedPseudoClass := fmt.Sprintf("%s%s", className, ensureLeadingColon(pseudoClass))
-			keys := sortedKeys(style)
-			builder.WriteString(fmt.Sprintf(".%s { ", formattedPseudoClass))
-			for _, prop := range keys {
-				builder.WriteString(fmt.Sprintf("%s: %s; ", prop, style[prop]))
-			}
-			builder.WriteString("} ")
+			w.rule("."+className+ensureLeadingColon(pseudoClass), composite.PseudoClasses[pseudoClass])
 		}
 
-		for pseudoElement, style := range composite.PseudoElements {
+		for _, pseudoElement := range sortedKeys(composite.PseudoElements) {
 			// Ensure pseudoElement starts with a double colon
-			formattedPseudoElement := fmt.Sprintf("%s%s", className, ensureDoubleLeadingColon(pseudoElement))
-			keys := sortedKeys(style)
-			builder.WriteString(fmt.Sprintf(".%s { ", formattedPseudoElement))
-			for _, prop := range keys {
-				builder.WriteString(fmt.Sprintf("%s: %s; ", prop, style[prop]))
-			}
-			builder.WriteString("} ")
+			w.rule("."+className+ensureDoubleLeadingColon(pseudoElement), composite.PseudoElements[pseudoElement])
 		}
 
-		for mediaQuery, style := range composite.MediaQueries {
+		for _, mediaQuery := range sortedQueries(composite.MediaQueries, "@media") {
 			// Ensure mediaQuery is correctly prefixed
-			formattedMediaQuery := ensureMediaPrefix(mediaQuery)
-			builder.WriteString(fmt.Sprintf("%s { .%s { ", formattedMediaQuery, className))
-			keys := sortedKeys(style)
-			for _, prop := range keys {
-				builder.WriteString(fmt.Sprintf("%s: %s; ", prop, style[prop]))
+			w.open(ensureMediaPrefix(mediaQuery))
+			w.rule("."+className, composite.MediaQueries[mediaQuery])
+			w.close()
+		}
+
+		for _, containerQuery := range sortedQueries(composite.ContainerQueries, "@container") {
+			w.open(ensureAtRulePrefix("@container", containerQuery))
+			w.rule("."+className, composite.ContainerQueries[containerQuery])
+			w.close()
+		}
+
+		for _, supportsQuery := range sortedKeys(composite.SupportsQueries) {
+			w.open(ensureAtRulePrefix("@supports", supportsQuery))
+			w.rule("."+className, composite.SupportsQueries[supportsQuery])
+			w.close()
+		}
+
+		if composite.Layer != "" {
+			w.close()
+		}
+	}
+
+	sm.writeAtoms(w)
+
+	for _, className := range sortedKeys(sm.nestedStyles) {
+		if w.classUsed(className) {
+			w.nested("."+className, sm.nestedStyles[className])
+		}
+	}
+
+	for _, suffix := range sortedKeys(sm.scopedStyles) {
+		sheet := sm.scopedStyles[suffix]
+		for _, selector := range sortedKeys(sheet) {
+			if w.selectorUsed(selector) {
+				w.rule(selector, sheet[selector])
 			}
-			builder.WriteString("} } ")
 		}
 	}
+}
+
+// cssWriter writes CSS rules, either spaced out or minified. If used is
+// set, only the rules of used classes and the referenced animations are
+// written. If referenced is set, the animation names used by the written
+// declarations are added to it.
+type cssWriter struct {
+	strings.Builder
+	minify     bool
+	used       func(className string) bool
+	animations map[string]struct{}
+	referenced map[string]struct{}
+}
 
-	return builder.String()
+// open starts a block, such as a rule or an at-rule, with the given prelude.
+func (w *cssWriter) open(prelude string) {
+	w.WriteString(prelude)
+	if w.minify {
+		w.WriteString("{")
+	} else {
+		w.WriteString(" { ")
+	}
+}
+
+// close ends a block.
+func (w *cssWriter) close() {
+	if w.minify {
+		w.WriteString("}")
+	} else {
+		w.WriteString("} ")
+	}
+}
+
+// statement writes an at-rule without a block, such as @import.
+func (w *cssWriter) statement(rule string) {
+	w.WriteString(rule)
+	if w.minify {
+		w.WriteString(";")
+	} else {
+		w.WriteString("; ")
+	}
+}
+
+// rule writes a rule with the declarations of style, sorted by property.
+func (w *cssWriter) rule(selector string, style Props) {
+	w.open(selector)
+	w.declarations(style)
+	w.close()
+}
+
+// declarations writes the declarations of style, sorted by property.
+func (w *cssWriter) declarations(style Props) {
+	for i, prop := range sortedKeys(style) {
+		if w.referenced != nil && (prop == Animation || prop == AnimationName) {
+			addAnimationNames(w.referenced, style[prop])
+		}
+		if w.minify {
+			if i > 0 {
+				w.WriteString(";")
+			}
+			w.WriteString(prop)
+			w.WriteString(":")
+			w.WriteString(style[prop])
+		} else {
+			w.WriteString(prop)
+			w.WriteString(": ")
+			w.WriteString(style[prop])
+			w.WriteString("; ")
+		}
+	}
 }
 
 // ensureLeadingColon ensures that the pseudoClass starts with a colon.
@@ -167,13 +341,21 @@ func ensureMediaPrefix(mediaQuery string) string {
 	return mediaQuery
 }
 
+// ensureAtRulePrefix ensures that the query starts with the given at-rule.
+func ensureAtRulePrefix(atRule, query string) string {
+	if !strings.HasPrefix(query, atRule) {
+		return atRule + " " + query
+	}
+	return query
+}
+
 // sortedKeys returns the keys of the map sorted alphanumerically
-func sortedKeys[T any](m map[string]T) []string {
-	keys := make([]string, 0, len(m))
+func sortedKeys[K ~string, T any](m map[K]T) []K {
+	keys := make([]K, 0, len(m))
 	for key := range m {
 		keys = append(keys, key)
 	}
-	sort.Strings(keys)
+	slices.Sort(keys)
 	return keys
 }
 
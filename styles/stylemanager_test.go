@@ -78,6 +78,32 @@ func TestMediaQuery(t *testing.T) {
 	assert.Equal(t, compositeStyle.MediaQueries, sm.compositeStyles[compositeClassName].MediaQueries)
 }
 
+func TestMediaQueriesOrderedByWidth(t *testing.T) {
+	sm := NewStyleManager()
+	mobileFirst := sm.AddCompositeStyle(CompositeStyle{
+		Default: Props{Padding: "4px"},
+		MediaQueries: map[string]Props{
+			"(min-width: 1024px)":         {Padding: "16px"},
+			"@media (min-width: 640px)":   {Padding: "8px"},
+			"screen and (orientation: x)": {Margin: "0"},
+		},
+	})
+	desktopFirst := sm.AddCompositeStyle(CompositeStyle{
+		ContainerQueries: map[string]Props{
+			"(max-width: 400px)":  {Padding: "8px"},
+			"(max-width: 1000px)": {Padding: "16px"},
+		},
+	})
+
+	css := sm.GenerateMinifiedCSS()
+	assert.Contains(t, css, "."+mobileFirst+"{padding:4px}"+
+		"@media (min-width: 640px){."+mobileFirst+"{padding:8px}}"+
+		"@media (min-width: 1024px){."+mobileFirst+"{padding:16px}}"+
+		"@media screen and (orientation: x){."+mobileFirst+"{margin:0}}")
+	assert.Contains(t, css, "@container (max-width: 1000px){."+desktopFirst+"{padding:16px}}"+
+		"@container (max-width: 400px){."+desktopFirst+"{padding:8px}}")
+}
+
 func TestGenerateCSS(t *testing.T) {
 	sm := NewStyleManager()
 
@@ -148,3 +174,37 @@ func TestAddStyleDeduplication(t *testing.T) {
 	assert.Equal(t, firstClassName, secondClassName, "Identical styles should return the same class name")
 	assert.Len(t, sm.styles, 1, "StyleManager should not duplicate identical styles")
 }
+
+func TestGenerateMinifiedCSS(t *testing.T) {
+	sm := NewStyleManager()
+	animationName := sm.AddAnimation(Keyframes{
+		"from": {"color": "red", "opacity": "0"},
+		"to":   {"color": "blue"},
+	})
+	className := sm.AddStyle(Props{"color": "red", "margin": "0 auto"})
+	compositeClassName := sm.AddCompositeStyle(CompositeStyle{
+		Default:        Props{"color": "pink"},
+		PseudoClasses:  map[string]Props{"hover": {"color": "blue"}},
+		PseudoElements: map[string]Props{"before": {"content": "'[x] '"}},
+		MediaQueries:   map[string]Props{"@media (min-width: 768px)": {"color": "green"}},
+	})
+
+	css := sm.GenerateMinifiedCSS()
+
+	assert.Equal(t, fmt.Sprintf(".%[1]s{color:red;margin:0 auto}"+
+		"@keyframes %[2]s{from{color:red;opacity:0}to{color:blue}}"+
+		".%[3]s{color:pink}.%[3]s:hover{color:blue}.%[3]s::before{content:'[x] '}"+
+		"@media (min-width: 768px){.%[3]s{color:green}}", className, animationName, compositeClassName), css)
+}
+
+func TestGenerateCSSIsDeterministic(t *testing.T) {
+	sm := NewStyleManager()
+	for i := 0; i < 20; i++ {
+		sm.AddStyle(Props{"z-index": fmt.Sprint(i)})
+	}
+
+	css := sm.GenerateCSS()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, css, sm.GenerateCSS())
+	}
+}
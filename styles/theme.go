@@ -0,0 +1,124 @@
+package styles
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ThemeAttribute is the attribute that selects a variant of a Theme for an
+// element and its descendants, as in attrs.Props{styles.ThemeAttribute: "dark"}.
+const ThemeAttribute = "data-theme"
+
+// Token is a design token, defined as a CSS custom property. Tokens are
+// usually declared once as variables and used through Var or VarOr:
+//
+//	var (
+//		Primary = styles.ColorToken("primary")
+//		SpaceMD = styles.SpaceToken("md")
+//	)
+//
+//	styles.Props{styles.Color: Primary.Var(), styles.Padding: SpaceMD.Var()}
+type Token string
+
+// NewToken returns a token for the custom property with the given name,
+// without the leading "--".
+func NewToken(name string) Token {
+	return Token(strings.TrimPrefix(name, "--"))
+}
+
+// ColorToken returns a token for a color, such as --color-primary.
+func ColorToken(name string) Token {
+	return Token("color-" + name)
+}
+
+// SpaceToken returns a token for a step of the spacing scale, such as
+// --space-md.
+func SpaceToken(name string) Token {
+	return Token("space-" + name)
+}
+
+// RadiusToken returns a token for a border radius, such as --radius-sm.
+func RadiusToken(name string) Token {
+	return Token("radius-" + name)
+}
+
+// FontToken returns a token for a font stack, such as --font-body.
+func FontToken(name string) Token {
+	return Token("font-" + name)
+}
+
+// ShadowToken returns a token for a box shadow, such as --shadow-lg.
+func ShadowToken(name string) Token {
+	return Token("shadow-" + name)
+}
+
+// Name returns the name of the custom property, such as "--color-primary".
+func (t Token) Name() string {
+	return "--" + string(t)
+}
+
+// Var returns the value of the token, such as "var(--color-primary)".
+func (t Token) Var() string {
+	return Var(string(t))
+}
+
+// VarOr returns the value of the token, or fallback where it isn't defined.
+func (t Token) VarOr(fallback string) string {
+	return "var(" + t.Name() + ", " + fallback + ")"
+}
+
+// Tokens maps tokens to their values.
+type Tokens map[Token]string
+
+// Theme represents a set of design tokens with variants overriding some of
+// them, such as a dark theme. Tokens are declared on :root, and each variant
+// applies to the elements with a ThemeAttribute of its name and their
+// descendants. MediaVariants maps media queries, such as
+// "(prefers-color-scheme: dark)", to the variant used when they match and no
+// variant is chosen on the root element.
+type Theme struct {
+	Tokens        Tokens
+	Variants      map[string]Tokens
+	MediaVariants map[string]string
+}
+
+// AddTheme adds the custom property declarations of a theme to the manager.
+// They are generated before any class, so the tokens can be used in all
+// styles.
+func (sm *StyleManager) AddTheme(theme Theme) {
+	key := fmt.Sprintf("%x", entityHash(theme))
+
+	if _, exists := sm.themes[key]; !exists {
+		sm.themes[key] = theme
+		sm.version++
+	}
+}
+
+func (theme Theme) writeCSS(w *cssWriter) {
+	if len(theme.Tokens) > 0 {
+		w.rule(":root", theme.Tokens.props())
+	}
+
+	for _, mediaQuery := range sortedKeys(theme.MediaVariants) {
+		variant, ok := theme.Variants[theme.MediaVariants[mediaQuery]]
+		if !ok {
+			continue
+		}
+		w.open(ensureMediaPrefix(mediaQuery))
+		w.rule(":root:not(["+ThemeAttribute+"])", variant.props())
+		w.close()
+	}
+
+	for _, name := range sortedKeys(theme.Variants) {
+		w.rule("["+ThemeAttribute+`="`+name+`"]`, theme.Variants[name].props())
+	}
+}
+
+// props returns the declarations of the custom properties.
+func (tokens Tokens) props() Props {
+	props := make(Props, len(tokens))
+	for token, value := range tokens {
+		props[token.Name()] = value
+	}
+	return props
+}
@@ -0,0 +1,50 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken(t *testing.T) {
+	primary := ColorToken("primary")
+
+	assert.Equal(t, "--color-primary", primary.Name())
+	assert.Equal(t, "var(--color-primary)", primary.Var())
+	assert.Equal(t, "var(--color-primary, #0055ff)", primary.VarOr("#0055ff"))
+	assert.Equal(t, "var(--space-md)", SpaceToken("md").Var())
+	assert.Equal(t, "--radius-sm", RadiusToken("sm").Name())
+	assert.Equal(t, "--font-body", FontToken("body").Name())
+	assert.Equal(t, "--shadow-lg", ShadowToken("lg").Name())
+	assert.Equal(t, NewToken("brand"), NewToken("--brand"))
+}
+
+func TestAddTheme(t *testing.T) {
+	var (
+		bg      = ColorToken("bg")
+		text    = ColorToken("text")
+		spaceMD = SpaceToken("md")
+	)
+	sm := NewStyleManager()
+	className := sm.AddStyle(Props{BackgroundColor: bg.Var(), Padding: spaceMD.Var()})
+	theme := Theme{
+		Tokens: Tokens{bg: "#fff", text: "#111", spaceMD: "1rem"},
+		Variants: map[string]Tokens{
+			"dark":     {bg: "#111", text: "#eee"},
+			"contrast": {text: "#000"},
+		},
+		MediaVariants: map[string]string{
+			"(prefers-color-scheme: dark)": "dark",
+			"print":                        "missing",
+		},
+	}
+	sm.AddTheme(theme)
+	sm.AddTheme(theme)
+
+	assert.Equal(t, ":root{--color-bg:#fff;--color-text:#111;--space-md:1rem}"+
+		"@media (prefers-color-scheme: dark){:root:not([data-theme]){--color-bg:#111;--color-text:#eee}}"+
+		`[data-theme="contrast"]{--color-text:#000}`+
+		`[data-theme="dark"]{--color-bg:#111;--color-text:#eee}`+
+		"."+className+"{background-color:var(--color-bg);padding:var(--space-md)}",
+		sm.GenerateMinifiedCSS())
+}
@@ -0,0 +1,70 @@
+package styles
+
+import (
+	"strings"
+	"unicode"
+)
+
+// GenerateUsedCSS generates the CSS of GenerateCSS, or GenerateMinifiedCSS if
+// minify is set, leaving out the classes for which used returns false and
+// the animations that the remaining rules don't refer to. Rules of scoped
+// stylesheets and global rules are left out if none of the classes in their
+// selector is used. Imports, layers, fonts, custom properties and themes are always
+// included.
+//
+// elem uses it to generate the CSS of only the classes of the rendered tree
+// when rendering with elem.RenderOptions{TreeShakeCSS: true}.
+func (sm *StyleManager) GenerateUsedCSS(used func(className string) bool, minify bool) string {
+	// Animations are referenced by name in the declarations, so the rules
+	// are written first to find the animations they use.
+	animations := make(map[string]struct{})
+	rules := cssWriter{minify: minify, used: used, animations: map[string]struct{}{}, referenced: animations}
+	sm.writeCSS(&rules)
+
+	w := cssWriter{minify: minify, used: used, animations: animations}
+	sm.writeCSS(&w)
+	return w.String()
+}
+
+// addAnimationNames adds the identifiers of an animation or animation-name
+// value to names. Names are compared as whole identifiers, so that the
+// animation "fade" isn't taken as used by "fade-out". Other parts of the
+// value, such as durations and timing functions, are added too but don't
+// match any animation.
+func addAnimationNames(names map[string]struct{}, value string) {
+	tokens := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '(' || r == ')' || unicode.IsSpace(r)
+	})
+	for _, token := range tokens {
+		names[strings.Trim(token, `"'`)] = struct{}{}
+	}
+}
+
+// classUsed reports whether the rules of a class should be written.
+func (w *cssWriter) classUsed(className string) bool {
+	return w.used == nil || w.used(className)
+}
+
+// animationUsed reports whether an animation should be written.
+func (w *cssWriter) animationUsed(animationName string) bool {
+	if w.used == nil {
+		return true
+	}
+	_, ok := w.animations[animationName]
+	return ok
+}
+
+// selectorUsed reports whether a rule with the given selector should be
+// written: if it has no classes, or if any of them is used.
+func (w *cssWriter) selectorUsed(selector string) bool {
+	if w.used == nil {
+		return true
+	}
+	hasClasses, used := false, false
+	scopeSelector(selector, func(className string) string {
+		hasClasses = true
+		used = used || w.used(className)
+		return className
+	})
+	return !hasClasses || used
+}
@@ -0,0 +1,61 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateUsedCSS(t *testing.T) {
+	sm := NewStyleManager()
+	sm.AddFontFace(FontFace{Family: "Inter", Sources: []FontSource{{URL: "inter.woff2"}}})
+	fade := sm.AddAnimation(Keyframes{"from": {Opacity: "0"}, "to": {Opacity: "1"}})
+	spin := sm.AddAnimation(Keyframes{"to": {Transform: "rotate(1turn)"}})
+	used := sm.AddStyle(Props{AnimationName: fade})
+	unused := sm.AddStyle(Props{AnimationName: spin})
+	composite := sm.AddCompositeStyle(CompositeStyle{Default: Props{Color: "red"}, MediaQueries: map[string]Props{"(min-width: 640px)": {Color: "blue"}}})
+	nested := sm.AddNestedStyle(Props{Color: "green"})
+	atoms := sm.AddAtomicCompositeStyle(CompositeStyle{MediaQueries: map[string]Props{"(min-width: 1024px)": {Margin: "0"}}})
+	scoped := sm.AddScopedStyles(StyleSheet{".card": {Padding: "1rem"}, ".list .item": {Margin: "0"}})
+
+	isUsed := func(className string) bool {
+		return className == used || className == composite || className == scoped["card"]
+	}
+	css := sm.GenerateUsedCSS(isUsed, true)
+
+	assert.Equal(t, "@font-face{font-family:'Inter';src:url('inter.woff2')}"+
+		"."+used+"{animation-name:"+fade+"}"+
+		"@keyframes "+fade+"{from{opacity:0}to{opacity:1}}"+
+		"."+composite+"{color:red}@media (min-width: 640px){."+composite+"{color:blue}}"+
+		"."+scoped["card"]+"{padding:1rem}", css)
+	assert.NotContains(t, css, unused)
+	assert.NotContains(t, css, spin)
+	assert.NotContains(t, css, nested)
+	assert.NotContains(t, css, atoms)
+	assert.NotContains(t, css, "1024px", "At-rules without used atomic classes should be left out")
+
+	all := func(string) bool { return true }
+	assert.Equal(t, sm.GenerateCSS(), sm.GenerateUsedCSS(all, false))
+	assert.Equal(t, sm.GenerateMinifiedCSS(), sm.GenerateUsedCSS(all, true))
+}
+
+func TestGenerateUsedCSSMatchesWholeAnimationNames(t *testing.T) {
+	sm := NewStyleManager()
+	sm.AddKeyframes("fade", Keyframes{"to": {Opacity: "0"}})
+	sm.AddKeyframes("in", Keyframes{"to": {Opacity: "1"}})
+	sm.AddKeyframes("fade-out", Keyframes{"to": {Opacity: "0"}})
+	sm.AddKeyframes("spin", Keyframes{"to": {Transform: "rotate(1turn)"}})
+	sm.AddKeyframes("pulse", Keyframes{"to": {Opacity: ".5"}})
+	sm.AddGlobalRules(
+		Rule{Selector: "body", Props: Props{Animation: "fade-out 1s linear, spin 2s cubic-bezier(0.4, 0, 0.2, 1)"}},
+		Rule{Selector: "main", Props: Props{AnimationName: `"pulse"`, Transition: "fade 1s"}},
+	)
+
+	css := sm.GenerateUsedCSS(func(string) bool { return true }, true)
+
+	assert.Contains(t, css, "@keyframes fade-out{")
+	assert.Contains(t, css, "@keyframes spin{")
+	assert.Contains(t, css, "@keyframes pulse{")
+	assert.NotContains(t, css, "@keyframes fade{", "fade-out and transitions shouldn't keep fade")
+	assert.NotContains(t, css, "@keyframes in{", "linear shouldn't keep in")
+}
@@ -0,0 +1,356 @@
+package styles
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Length is a CSS length or percentage. Lengths can be added and scaled, and
+// are written as a calc() expression when their units differ:
+//
+//	styles.LengthPercent(100).Sub(styles.LengthRem(2)).String() // "calc(100% - 2rem)"
+type Length struct{ terms quantity }
+
+// Angle is a CSS angle, with the same arithmetic as Length.
+type Angle struct{ terms quantity }
+
+// Time is a CSS duration, with the same arithmetic as Length.
+type Time struct{ terms quantity }
+
+// Len returns a length in the given unit, such as "ch" or "dvh".
+func Len(value float64, unit string) Length { return Length{unitTerm(value, unit)} }
+
+// LengthPx returns a length in pixels. Unlike Pixels, which returns a
+// string, the result can be used in calculations.
+func LengthPx(value float64) Length { return Len(value, "px") }
+
+// LengthRem returns a length in rem, the typed counterpart of Rem.
+func LengthRem(value float64) Length { return Len(value, "rem") }
+
+// LengthEm returns a length in em, the typed counterpart of Em.
+func LengthEm(value float64) Length { return Len(value, "em") }
+
+// LengthPercent returns a percentage, the typed counterpart of Percent.
+func LengthPercent(value float64) Length { return Len(value, "%") }
+
+// LengthVw returns a length in percent of the viewport's width, the typed
+// counterpart of ViewportWidth.
+func LengthVw(value float64) Length { return Len(value, "vw") }
+
+// LengthVh returns a length in percent of the viewport's height, the typed
+// counterpart of ViewportHeight.
+func LengthVh(value float64) Length { return Len(value, "vh") }
+
+// LengthFr returns a fraction of the free space in a grid container.
+func LengthFr(value float64) Length { return Len(value, "fr") }
+
+// Length returns the value of the token as a Length, so it can be used in
+// calculations.
+func (t Token) Length() Length { return Length{exprTerm(t.Var())} }
+
+// Add returns the sum of l and other. It panics if the sum mixes fr with
+// other units, as calc() doesn't accept fr.
+func (l Length) Add(other Length) Length { return Length{l.terms.add(other.terms, 1)}.checkFr() }
+
+// Sub returns the difference of l and other. Like Add, it panics if the
+// result mixes fr with other units.
+func (l Length) Sub(other Length) Length { return Length{l.terms.add(other.terms, -1)}.checkFr() }
+
+// Mul returns l multiplied by factor.
+func (l Length) Mul(factor float64) Length { return Length{l.terms.mul(factor)} }
+
+// Div returns l divided by divisor.
+func (l Length) Div(divisor float64) Length { return Length{l.terms.mul(1 / divisor)} }
+
+// String returns the length as a CSS value.
+func (l Length) String() string { return l.terms.format("0") }
+
+// checkFr panics if l would be written as a calc() expression containing
+// fr, which isn't a length and so can't be combined with other units.
+func (l Length) checkFr() Length {
+	terms := l.terms.nonZero()
+	if len(terms) > 1 && slices.ContainsFunc(terms, isFrTerm) {
+		panic("styles: fr can't be combined with other units, as calc() doesn't accept it")
+	}
+	return l
+}
+
+func isFrTerm(t term) bool { return !t.expr && t.unit == "fr" }
+
+// AngleDeg returns an angle in degrees.
+func AngleDeg(value float64) Angle { return Angle{unitTerm(value, "deg")} }
+
+// AngleTurn returns an angle in turns.
+func AngleTurn(value float64) Angle { return Angle{unitTerm(value, "turn")} }
+
+// Add returns the sum of a and other.
+func (a Angle) Add(other Angle) Angle { return Angle{a.terms.add(other.terms, 1)} }
+
+// Sub returns the difference of a and other.
+func (a Angle) Sub(other Angle) Angle { return Angle{a.terms.add(other.terms, -1)} }
+
+// Mul returns a multiplied by factor.
+func (a Angle) Mul(factor float64) Angle { return Angle{a.terms.mul(factor)} }
+
+// Div returns a divided by divisor.
+func (a Angle) Div(divisor float64) Angle { return Angle{a.terms.mul(1 / divisor)} }
+
+// String returns the angle as a CSS value.
+func (a Angle) String() string { return a.terms.format("0deg") }
+
+// TimeMs returns a duration in milliseconds, the typed counterpart of
+// Milliseconds.
+func TimeMs(value float64) Time { return Time{unitTerm(value, "ms")} }
+
+// TimeSec returns a duration in seconds, the typed counterpart of Seconds.
+func TimeSec(value float64) Time { return Time{unitTerm(value, "s")} }
+
+// Add returns the sum of t and other.
+func (t Time) Add(other Time) Time { return Time{t.terms.add(other.terms, 1)} }
+
+// Sub returns the difference of t and other.
+func (t Time) Sub(other Time) Time { return Time{t.terms.add(other.terms, -1)} }
+
+// Mul returns t multiplied by factor.
+func (t Time) Mul(factor float64) Time { return Time{t.terms.mul(factor)} }
+
+// Div returns t divided by divisor.
+func (t Time) Div(divisor float64) Time { return Time{t.terms.mul(1 / divisor)} }
+
+// String returns the duration as a CSS value.
+func (t Time) String() string { return t.terms.format("0s") }
+
+// Clamp returns a length of preferred, but no less than min and no more than
+// max. Like Min and Max, it panics if given an fr length.
+func Clamp(min, preferred, max Length) Length {
+	checkNoFr("clamp", min, preferred, max)
+	return Length{exprTerm("clamp(" + min.String() + ", " + preferred.String() + ", " + max.String() + ")")}
+}
+
+// Min returns the smallest of the lengths.
+func Min(lengths ...Length) Length {
+	checkNoFr("min", lengths...)
+	return Length{exprTerm("min(" + joinValues(lengths, ", ") + ")")}
+}
+
+// Max returns the largest of the lengths.
+func Max(lengths ...Length) Length {
+	checkNoFr("max", lengths...)
+	return Length{exprTerm("max(" + joinValues(lengths, ", ") + ")")}
+}
+
+// checkNoFr panics if any of the arguments of a math function contains fr.
+func checkNoFr(function string, lengths ...Length) {
+	for _, l := range lengths {
+		if slices.ContainsFunc(l.terms, isFrTerm) {
+			panic("styles: " + function + "() doesn't accept fr")
+		}
+	}
+}
+
+// MinMax returns a grid track size between min and max.
+func MinMax(min, max Length) string {
+	return "minmax(" + min.String() + ", " + max.String() + ")"
+}
+
+// Counts of repeated grid tracks that fill the container.
+const (
+	AutoFill = "auto-fill"
+	AutoFit  = "auto-fit"
+)
+
+// Repeat returns a repeat() of grid tracks. count is a number, AutoFill or
+// AutoFit, and the tracks are lengths or sizes such as MinMax:
+//
+//	styles.Repeat(styles.AutoFill, styles.MinMax(styles.LengthPx(200), styles.LengthFr(1)))
+func Repeat(count any, tracks ...any) string {
+	sizes := make([]string, len(tracks))
+	for i, track := range tracks {
+		sizes[i] = fmt.Sprint(track)
+	}
+	return "repeat(" + fmt.Sprint(count) + ", " + strings.Join(sizes, " ") + ")"
+}
+
+// ColorStop returns a color stop of a gradient, with optional positions.
+func ColorStop(color string, positions ...Length) string {
+	if len(positions) == 0 {
+		return color
+	}
+	return color + " " + joinValues(positions, " ")
+}
+
+// LinearGradient returns a linear-gradient() in the given direction, such as
+// "to right" or AngleDeg(45).String(). An empty direction goes to the bottom.
+func LinearGradient(direction string, stops ...string) string {
+	return gradient("linear-gradient", direction, stops)
+}
+
+// RadialGradient returns a radial-gradient() of the given shape, such as
+// "circle at center". An empty shape is an ellipse at the center.
+func RadialGradient(shape string, stops ...string) string {
+	return gradient("radial-gradient", shape, stops)
+}
+
+func gradient(name, shape string, stops []string) string {
+	if shape != "" {
+		stops = append([]string{shape}, stops...)
+	}
+	return name + "(" + strings.Join(stops, ", ") + ")"
+}
+
+// Translate returns a translate() transform function.
+func Translate(x, y Length) string {
+	return "translate(" + x.String() + ", " + y.String() + ")"
+}
+
+// TranslateX returns a translateX() transform function.
+func TranslateX(x Length) string {
+	return "translateX(" + x.String() + ")"
+}
+
+// TranslateY returns a translateY() transform function.
+func TranslateY(y Length) string {
+	return "translateY(" + y.String() + ")"
+}
+
+// Rotate returns a rotate() transform function.
+func Rotate(angle Angle) string {
+	return "rotate(" + angle.String() + ")"
+}
+
+// Scale returns a scale() transform function.
+func Scale(x, y float64) string {
+	if x == y {
+		return "scale(" + formatFloat(x, 4) + ")"
+	}
+	return "scale(" + formatFloat(x, 4) + ", " + formatFloat(y, 4) + ")"
+}
+
+// Skew returns a skew() transform function.
+func Skew(x, y Angle) string {
+	return "skew(" + x.String() + ", " + y.String() + ")"
+}
+
+// Transforms returns a list of transform functions, applied from left to
+// right.
+func Transforms(functions ...string) string {
+	return strings.Join(functions, " ")
+}
+
+// Shadow is a layer of a box-shadow.
+type Shadow struct {
+	X, Y, Blur, Spread Length
+	Color              string
+	Inset              bool
+}
+
+// String returns the shadow as a CSS value.
+func (s Shadow) String() string {
+	parts := make([]string, 0, 6)
+	if s.Inset {
+		parts = append(parts, "inset")
+	}
+	parts = append(parts, s.X.String(), s.Y.String(), s.Blur.String())
+	if len(s.Spread.terms.nonZero()) > 0 {
+		parts = append(parts, s.Spread.String())
+	}
+	if s.Color != "" {
+		parts = append(parts, s.Color)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Shadows returns a box-shadow of several layers, the first on top.
+func Shadows(shadows ...Shadow) string {
+	return joinValues(shadows, ", ")
+}
+
+func joinValues[T fmt.Stringer](values []T, sep string) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = v.String()
+	}
+	return strings.Join(s, sep)
+}
+
+// quantity is a sum of terms, each a value in a unit or a multiple of an
+// expression such as var() or min().
+type quantity []term
+
+type term struct {
+	value float64
+	unit  string
+	expr  bool
+}
+
+func unitTerm(value float64, unit string) quantity {
+	return quantity{{value: value, unit: unit}}
+}
+
+func exprTerm(expr string) quantity {
+	return quantity{{value: 1, unit: expr, expr: true}}
+}
+
+// add returns the sum of q and sign times other, combining terms of the
+// same unit.
+func (q quantity) add(other quantity, sign float64) quantity {
+	sum := slices.Clone(q)
+	for _, t := range other {
+		t.value *= sign
+		if i := slices.IndexFunc(sum, func(s term) bool { return s.unit == t.unit && s.expr == t.expr }); i >= 0 {
+			sum[i].value += t.value
+		} else {
+			sum = append(sum, t)
+		}
+	}
+	return sum
+}
+
+func (q quantity) mul(factor float64) quantity {
+	product := slices.Clone(q)
+	for i := range product {
+		product[i].value *= factor
+	}
+	return product
+}
+
+func (q quantity) nonZero() quantity {
+	return slices.DeleteFunc(slices.Clone(q), func(t term) bool { return t.value == 0 })
+}
+
+// format returns the quantity as a CSS value, or zero if it has no terms.
+func (q quantity) format(zero string) string {
+	terms := q.nonZero()
+	switch {
+	case len(terms) == 0:
+		return zero
+	case len(terms) == 1 && (!terms[0].expr || terms[0].value == 1):
+		return terms[0].format(terms[0].value)
+	}
+
+	var b strings.Builder
+	b.WriteString("calc(")
+	for i, t := range terms {
+		switch {
+		case i == 0:
+			b.WriteString(t.format(t.value))
+		case t.value < 0:
+			b.WriteString(" - " + t.format(-t.value))
+		default:
+			b.WriteString(" + " + t.format(t.value))
+		}
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func (t term) format(value float64) string {
+	if !t.expr {
+		return formatFloat(value, 4) + t.unit
+	}
+	if value == 1 {
+		return t.unit
+	}
+	return formatFloat(value, 4) + " * " + t.unit
+}
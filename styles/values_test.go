@@ -0,0 +1,76 @@
+package styles
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLength(t *testing.T) {
+	assert.Equal(t, "1.5rem", LengthRem(1.5).String())
+	assert.Equal(t, "0.3333px", LengthPx(1).Div(3).String(), "Values should have at most 4 decimals")
+	assert.Equal(t, "10px", LengthPx(4).Add(LengthPx(6)).String(), "Lengths of the same unit should be combined")
+	assert.Equal(t, "calc(100% - 2rem)", LengthPercent(100).Sub(LengthRem(2)).String())
+	assert.Equal(t, "calc(50vw + 1em - 12px)", LengthVw(50).Add(LengthEm(1)).Sub(LengthPx(12)).String())
+	assert.Equal(t, "calc(20% - 6px)", LengthPercent(10).Sub(LengthPx(3)).Mul(2).String())
+	assert.Equal(t, "0", LengthPx(2).Sub(LengthPx(2)).String())
+	assert.Equal(t, "0", Length{}.String())
+	assert.Equal(t, "-4px", LengthPx(-4).String())
+	assert.Equal(t, "2ch", Len(2, "ch").String())
+
+	space := SpaceToken("md").Length()
+	assert.Equal(t, "var(--space-md)", space.String())
+	assert.Equal(t, "calc(2 * var(--space-md))", space.Mul(2).String())
+	assert.Equal(t, "calc(100% - 2 * var(--space-md))", LengthPercent(100).Sub(space.Mul(2)).String())
+}
+
+func TestLengthFr(t *testing.T) {
+	assert.Equal(t, "3fr", LengthFr(1).Add(LengthFr(2)).String())
+	assert.Equal(t, "1.5fr", LengthFr(3).Div(2).String())
+	assert.Panics(t, func() { LengthFr(1).Add(LengthPx(10)) })
+	assert.Panics(t, func() { LengthPercent(100).Sub(LengthFr(1)) })
+	assert.Panics(t, func() { Min(LengthFr(1), LengthPx(200)) })
+	assert.Panics(t, func() { Clamp(LengthPx(100), LengthFr(1), LengthPx(200)) })
+}
+
+func TestAngleAndTime(t *testing.T) {
+	assert.Equal(t, "calc(0.25turn + 10deg)", AngleTurn(0.25).Add(AngleDeg(10)).String())
+	assert.Equal(t, "0deg", AngleDeg(0).String())
+	assert.Equal(t, "150ms", TimeMs(300).Div(2).String())
+	assert.Equal(t, "calc(1s + 200ms)", TimeSec(1).Add(TimeMs(200)).String())
+	assert.Equal(t, "0s", Time{}.String())
+}
+
+func TestMathFunctions(t *testing.T) {
+	assert.Equal(t, "clamp(1rem, calc(1rem + 2vw), 3rem)", Clamp(LengthRem(1), LengthRem(1).Add(LengthVw(2)), LengthRem(3)).String())
+	assert.Equal(t, "min(100%, 60rem)", Min(LengthPercent(100), LengthRem(60)).String())
+	assert.Equal(t, "max(50vh, 300px)", Max(LengthVh(50), LengthPx(300)).String())
+	assert.Equal(t, "calc(max(50vh, 300px) - 2rem)", Max(LengthVh(50), LengthPx(300)).Sub(LengthRem(2)).String())
+}
+
+func TestGridTracks(t *testing.T) {
+	assert.Equal(t, "minmax(200px, 1fr)", MinMax(LengthPx(200), LengthFr(1)))
+	assert.Equal(t, "repeat(auto-fill, minmax(200px, 1fr))", Repeat(AutoFill, MinMax(LengthPx(200), LengthFr(1))))
+	assert.Equal(t, "repeat(3, 1fr 2fr)", Repeat(3, LengthFr(1), LengthFr(2)))
+}
+
+func TestGradients(t *testing.T) {
+	assert.Equal(t, "linear-gradient(45deg, red, blue 50%, green 75% 100%)",
+		LinearGradient(AngleDeg(45).String(), "red", ColorStop("blue", LengthPercent(50)), ColorStop("green", LengthPercent(75), LengthPercent(100))))
+	assert.Equal(t, "linear-gradient(red, blue)", LinearGradient("", "red", "blue"))
+	assert.Equal(t, "radial-gradient(circle at center, white, black)", RadialGradient("circle at center", "white", "black"))
+}
+
+func TestTransforms(t *testing.T) {
+	assert.Equal(t, "translate(-50%, calc(-50% + 4px)) rotate(0.125turn) scale(1.5) scale(1, 2) skew(10deg, 0deg)",
+		Transforms(Translate(LengthPercent(-50), LengthPercent(-50).Add(LengthPx(4))), Rotate(AngleTurn(0.125)), Scale(1.5, 1.5), Scale(1, 2), Skew(AngleDeg(10), AngleDeg(0))))
+	assert.Equal(t, "translateX(1rem) translateY(2px)", Transforms(TranslateX(LengthRem(1)), TranslateY(LengthPx(2))))
+}
+
+func TestShadows(t *testing.T) {
+	assert.Equal(t, "0 1px 2px rgb(0 0 0 / 0.2), inset 0 0 0 1px #336699",
+		Shadows(
+			Shadow{Y: LengthPx(1), Blur: LengthPx(2), Color: MustParseColor("black").WithAlpha(0.2).RGB()},
+			Shadow{Spread: LengthPx(1), Color: "#336699", Inset: true},
+		))
+}
@@ -0,0 +1,244 @@
+// Package stylesheet serves the CSS of a StyleManager as an external
+// stylesheet, so browsers can cache it instead of downloading it inlined in
+// every page.
+package stylesheet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+)
+
+// keptVersions is how many versions of the CSS are still served after the
+// styles change, for pages rendered before the change.
+const keptVersions = 16
+
+// Options configures a Stylesheet.
+type Options struct {
+	// Prefix is the path the Stylesheet is served under, such as "/assets/".
+	// It defaults to "/".
+	Prefix string
+	// Name is the name of the file before its hash. It defaults to "styles".
+	Name string
+	// Minify serves the minified CSS, if the generator supports it.
+	Minify bool
+	// Brotli, if set, compresses the CSS for clients that accept brotli, e.g.
+	// with a function from a brotli package. Without it, only gzip is used.
+	Brotli func(css []byte) ([]byte, error)
+}
+
+// Stylesheet is an http.Handler serving the CSS of a generator at a URL
+// containing a hash of the CSS, such as "/assets/styles.1a2b3c4d5e6f7a8b.css".
+// The URL changes whenever the CSS does, so responses are cached by browsers
+// for good. Gzip and brotli variants are compressed once per version of the
+// CSS.
+//
+// A Stylesheet can be used by concurrent requests, but its generator must not
+// be changed meanwhile: a styles.StyleManager isn't safe for concurrent use,
+// so styles must be added before the pages using them are served, or by one
+// goroutine at a time with the rendering and serving of the stylesheet.
+type Stylesheet struct {
+	generator elem.CSSGenerator
+	opts      Options
+
+	mu       sync.Mutex
+	current  *asset
+	version  uint64
+	assets   map[string]*asset
+	versions []string
+}
+
+// versioned is implemented by generators that can tell whether their CSS
+// may have changed, such as styles.StyleManager.
+type versioned interface {
+	Version() uint64
+}
+
+// asset is a version of the CSS.
+type asset struct {
+	name   string
+	hash   string
+	css    string
+	plain  []byte
+	gzip   []byte
+	brotli []byte
+}
+
+// New returns a Stylesheet serving the CSS of generator, usually a
+// styles.StyleManager.
+func New(generator elem.CSSGenerator, opts Options) *Stylesheet {
+	if opts.Prefix == "" {
+		opts.Prefix = "/"
+	}
+	if !strings.HasSuffix(opts.Prefix, "/") {
+		opts.Prefix += "/"
+	}
+	if opts.Name == "" {
+		opts.Name = "styles"
+	}
+	return &Stylesheet{generator: generator, opts: opts, assets: make(map[string]*asset)}
+}
+
+// URL returns the URL of the current CSS. Styles added to the generator
+// afterwards get a new URL. If the generator has a Version method, as
+// styles.StyleManager does, the CSS is only generated again once the version
+// changes.
+func (s *Stylesheet) URL() string {
+	return s.opts.Prefix + s.refresh().name
+}
+
+// Link returns a node rendering a <link rel="stylesheet"> to the CSS as it is
+// when the node is rendered, to put in the head of pages instead of passing
+// the StyleManager to RenderOptions. Styles must be added to the generator
+// before the link is rendered to be included.
+func (s *Stylesheet) Link() elem.Node {
+	return elem.Lazy(func() elem.Node {
+		return elem.Link(attrs.Props{attrs.Rel: "stylesheet", attrs.Href: s.URL()})
+	})
+}
+
+// ServeHTTP serves the versions of the CSS whose URL was returned by URL or
+// rendered by Link, and responds with 404 Not Found to other paths, without
+// generating the CSS.
+func (s *Stylesheet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, s.opts.Prefix)
+	a := s.lookup(name)
+	if a == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, encoding := a.plain, ""
+	switch {
+	case a.brotli != nil && accepts(r, "br"):
+		body, encoding = a.brotli, "br"
+	case accepts(r, "gzip"):
+		body, encoding = a.gzip, "gzip"
+	}
+	etag := `"` + a.hash + `"`
+	if encoding != "" {
+		etag = `"` + a.hash + "-" + encoding + `"`
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/css; charset=utf-8")
+	h.Set("Cache-Control", "public, max-age=31536000, immutable")
+	h.Set("Vary", "Accept-Encoding")
+	h.Set("ETag", etag)
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if encoding != "" {
+		h.Set("Content-Encoding", encoding)
+	}
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+func (s *Stylesheet) lookup(name string) *asset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.assets[name]
+}
+
+// refresh returns the asset of the current CSS, creating it if the CSS has
+// changed.
+func (s *Stylesheet) refresh() *asset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, isVersioned := s.generator.(versioned)
+	if isVersioned && s.current != nil && v.Version() == s.version {
+		return s.current
+	}
+	if isVersioned {
+		s.version = v.Version()
+	}
+
+	css := s.generate()
+	if s.current != nil && s.current.css == css {
+		return s.current
+	}
+
+	sum := sha256.Sum256([]byte(css))
+	hash := hex.EncodeToString(sum[:8])
+	name := s.opts.Name + "." + hash + ".css"
+	if a, ok := s.assets[name]; ok {
+		s.current = a
+		return a
+	}
+
+	a := &asset{name: name, hash: hash, css: css, plain: []byte(css)}
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	zw.Write(a.plain)
+	zw.Close()
+	a.gzip = buf.Bytes()
+	if s.opts.Brotli != nil {
+		// Clients fall back to gzip if brotli compression fails.
+		if compressed, err := s.opts.Brotli(a.plain); err == nil {
+			a.brotli = compressed
+		}
+	}
+
+	s.assets[name] = a
+	s.versions = append(s.versions, name)
+	if len(s.versions) > keptVersions {
+		delete(s.assets, s.versions[0])
+		s.versions = s.versions[1:]
+	}
+	s.current = a
+	return a
+}
+
+func (s *Stylesheet) generate() string {
+	if m, ok := s.generator.(elem.MinifiedCSSGenerator); ok && s.opts.Minify {
+		return m.GenerateMinifiedCSS()
+	}
+	return s.generator.GenerateCSS()
+}
+
+// accepts reports whether the request accepts the content coding.
+func accepts(r *http.Request, coding string) bool {
+	for _, accepted := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(accepted, ";")
+		if strings.TrimSpace(name) != coding {
+			continue
+		}
+		q, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+		v, err := strconv.ParseFloat(q, 64)
+		return err == nil && v > 0
+	}
+	return false
+}
+
+// matchesETag reports whether an If-None-Match header matches etag.
+func matchesETag(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package stylesheet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/styles"
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, h http.Handler, path string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStylesheet(t *testing.T) {
+	sm := styles.NewStyleManager()
+	className := sm.AddStyle(styles.Props{styles.Color: "red"})
+	sheet := New(sm, Options{Prefix: "/assets", Minify: true})
+
+	page := elem.Html(nil, elem.Head(nil, sheet.Link()), elem.Body(nil))
+	html := page.Render()
+	url := sheet.URL()
+	assert.Regexp(t, `^/assets/styles\.[0-9a-f]{16}\.css$`, url)
+	assert.Equal(t, `<!DOCTYPE html><html><head><link href="`+url+`" rel="stylesheet"></head><body></body></html>`, html)
+
+	rec := get(t, sheet, url, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "."+className+"{color:red}", rec.Body.String())
+	assert.Equal(t, "text/css; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	etag := rec.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{16}"$`, etag)
+
+	rec = get(t, sheet, url, http.Header{"If-None-Match": {`W/"other", ` + etag}})
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = get(t, sheet, url, http.Header{"Accept-Encoding": {"deflate, gzip;q=0.8"}})
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"), "Each encoding should have its own ETag")
+	zr, err := gzip.NewReader(rec.Body)
+	assert.NoError(t, err)
+	css, _ := io.ReadAll(zr)
+	assert.Equal(t, "."+className+"{color:red}", string(css))
+
+	rec = get(t, sheet, url, http.Header{"Accept-Encoding": {"gzip;q=0"}})
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+
+	assert.Equal(t, http.StatusNotFound, get(t, sheet, "/assets/styles.0000000000000000.css", nil).Code)
+	req := httptest.NewRequest(http.MethodPost, url, nil)
+	rec = httptest.NewRecorder()
+	sheet.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestStylesheetVersions(t *testing.T) {
+	sm := styles.NewStyleManager()
+	sm.AddStyle(styles.Props{styles.Color: "red"})
+	sheet := New(sm, Options{})
+
+	first := sheet.URL()
+	assert.Equal(t, first, sheet.URL(), "The URL should only change with the CSS")
+	blue := sm.AddStyle(styles.Props{styles.Color: "blue"})
+	second := sheet.URL()
+
+	assert.NotEqual(t, first, second)
+	assert.Regexp(t, regexp.MustCompile(`^/styles\.[0-9a-f]{16}\.css$`), second)
+	assert.Equal(t, http.StatusOK, get(t, sheet, first, nil).Code, "Previous versions should still be served")
+	assert.Contains(t, get(t, sheet, second, nil).Body.String(), blue)
+
+	// A new process with the same styles serves URLs rendered before it
+	// started once it renders them itself.
+	restarted := New(sm, Options{})
+	assert.Equal(t, http.StatusNotFound, get(t, restarted, second, nil).Code)
+	assert.Equal(t, second, restarted.URL())
+	assert.Equal(t, http.StatusOK, get(t, restarted, second, nil).Code)
+
+	for i := 0; i < keptVersions; i++ {
+		sm.AddStyle(styles.Props{styles.ZIndex: styles.Int(i)})
+		sheet.URL()
+	}
+	assert.Equal(t, http.StatusNotFound, get(t, sheet, first, nil).Code, "Old versions should eventually be dropped")
+}
+
+// countingGenerator counts the calls to GenerateCSS.
+type countingGenerator struct {
+	*styles.StyleManager
+	calls int
+}
+
+func (g *countingGenerator) GenerateCSS() string {
+	g.calls++
+	return g.StyleManager.GenerateCSS()
+}
+
+func TestStylesheetGeneratesOnChange(t *testing.T) {
+	g := &countingGenerator{StyleManager: styles.NewStyleManager()}
+	g.AddStyle(styles.Props{styles.Color: "red"})
+	sheet := New(g, Options{})
+
+	first := sheet.URL()
+	sheet.URL()
+	get(t, sheet, "/styles.0000000000000000.css", nil)
+	assert.Equal(t, 1, g.calls, "The CSS should only be generated when the styles change")
+
+	g.AddStyle(styles.Props{styles.Color: "red"})
+	assert.Equal(t, first, sheet.URL())
+	assert.Equal(t, 1, g.calls, "Adding an existing style shouldn't change the version")
+
+	g.AddStyle(styles.Props{styles.Color: "blue"})
+	assert.NotEqual(t, first, sheet.URL())
+	assert.Equal(t, 2, g.calls)
+}
+
+func TestStylesheetConcurrentUse(t *testing.T) {
+	sm := styles.NewStyleManager()
+	sm.AddStyle(styles.Props{styles.Color: "red"})
+	sheet := New(sm, Options{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			elem.Head(nil, sheet.Link()).Render()
+			get(t, sheet, sheet.URL(), nil)
+			get(t, sheet, "/unknown.css", nil)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestStylesheetBrotli(t *testing.T) {
+	sm := styles.NewStyleManager()
+	sm.AddStyle(styles.Props{styles.Color: "red"})
+	sheet := New(sm, Options{
+		Prefix: "/css/",
+		Name:   "app",
+		Brotli: func(css []byte) ([]byte, error) { return append([]byte("br:"), css...), nil },
+	})
+	url := sheet.URL()
+	assert.Regexp(t, `^/css/app\.[0-9a-f]{16}\.css$`, url)
+
+	rec := get(t, sheet, url, http.Header{"Accept-Encoding": {"gzip, deflate, br"}})
+	assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+	assert.True(t, bytes.HasPrefix(rec.Body.Bytes(), []byte("br:")))
+
+	req := httptest.NewRequest(http.MethodHead, url, nil)
+	req.Header.Set("Accept-Encoding", "br")
+	rec = httptest.NewRecorder()
+	sheet.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.NotEqual(t, "0", rec.Header().Get("Content-Length"))
+}
@@ -0,0 +1,283 @@
+// Package table renders data tables from slices of structs, with sortable
+// column headers, pagination controls and an empty-state row.
+//
+// Sort and page state travels in the query string, so a table works as plain
+// links and, when Options.HXTarget is set, reloads in place with htmx.
+package table
+
+import (
+	"html"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/chasefleming/elem-go/htmx"
+)
+
+// Query parameters used in the generated sort and pagination links.
+const (
+	SortParam  = "sort"
+	OrderParam = "order"
+	PageParam  = "page"
+)
+
+// Sort orders, as used in the OrderParam query parameter.
+const (
+	Ascending  = "asc"
+	Descending = "desc"
+)
+
+// Column describes one column of a table of T.
+type Column[T any] struct {
+	// Header is the text of the column's <th>.
+	Header string
+	// Cell renders the content of the column's cell for a row.
+	Cell func(T) elem.Node
+	// SortKey identifies the column in sort links. Columns without one are
+	// not sortable.
+	SortKey string
+	// Compare orders two rows by this column. When it is set, the table sorts
+	// the rows itself; leave it nil when rows arrive already sorted, e.g.
+	// from an ORDER BY query.
+	Compare func(a, b T) int
+	// Attrs are applied to the column's header and body cells.
+	Attrs attrs.Props
+}
+
+// Options configures a table's state and links.
+type Options struct {
+	// BaseURL is the URL sort and page links point to. Its existing query
+	// parameters are preserved.
+	BaseURL string
+	// Sort is the SortKey of the active sort column and Order its direction
+	// (Ascending or Descending). Use FromQuery to read them from a request.
+	Sort  string
+	Order string
+	// Page is the 1-based current page and PerPage the number of rows per
+	// page. Pagination is disabled when PerPage is zero.
+	Page    int
+	PerPage int
+	// TotalRows is the size of the whole data set when rows holds only the
+	// current page. When it is zero, rows is treated as the whole data set
+	// and paginated in memory.
+	TotalRows int
+	// Caption is rendered as the table's <caption> when set.
+	Caption string
+	// Empty is rendered in a single full-width row when there are no rows.
+	// It defaults to the text "No results".
+	Empty elem.Node
+	// HXTarget, when set, adds hx-get and hx-target to every sort and page
+	// link so the table reloads in place. HXSwap optionally sets hx-swap.
+	HXTarget string
+	HXSwap   string
+	// Attrs are applied to the <table> element.
+	Attrs attrs.Props
+}
+
+// FromQuery reads the sort order and page from a request's query string
+// into a copy of opts.
+func FromQuery(query url.Values, opts Options) Options {
+	if sort := query.Get(SortParam); sort != "" {
+		opts.Sort = sort
+		opts.Order = Ascending
+		if query.Get(OrderParam) == Descending {
+			opts.Order = Descending
+		}
+	}
+	if page, err := strconv.Atoi(query.Get(PageParam)); err == nil && page > 0 {
+		opts.Page = page
+	}
+	return opts
+}
+
+// New renders rows as a table with the given columns. The result is a
+// <div> holding the <table> and, when paginated, a pagination <nav>.
+func New[T any](rows []T, columns []Column[T], opts Options) *elem.Element {
+	if opts.Page < 1 {
+		opts.Page = 1
+	}
+
+	total := opts.TotalRows
+	if total == 0 {
+		total = len(rows)
+		rows = sortRows(rows, columns, opts)
+		if opts.PerPage > 0 {
+			start := min((opts.Page-1)*opts.PerPage, len(rows))
+			end := min(start+opts.PerPage, len(rows))
+			rows = rows[start:end]
+		}
+	}
+
+	children := []elem.Node{}
+	if opts.Caption != "" {
+		children = append(children, elem.Caption(nil, elem.Text(opts.Caption)))
+	}
+	children = append(children,
+		elem.THead(nil, renderHeader(columns, opts)),
+		elem.TBody(nil, renderBody(rows, columns, opts)...),
+	)
+
+	table := elem.Table(opts.Attrs, children...)
+	if opts.PerPage <= 0 || total <= opts.PerPage {
+		return elem.Div(nil, table)
+	}
+	pages := (total + opts.PerPage - 1) / opts.PerPage
+	return elem.Div(nil, table, renderPagination(pages, opts))
+}
+
+// sortRows returns a sorted copy of rows when the active sort column has a
+// Compare function, and rows unchanged otherwise.
+func sortRows[T any](rows []T, columns []Column[T], opts Options) []T {
+	for _, col := range columns {
+		if col.SortKey == "" || col.SortKey != opts.Sort || col.Compare == nil {
+			continue
+		}
+		sorted := slices.Clone(rows)
+		slices.SortStableFunc(sorted, func(a, b T) int {
+			if opts.Order == Descending {
+				return col.Compare(b, a)
+			}
+			return col.Compare(a, b)
+		})
+		return sorted
+	}
+	return rows
+}
+
+func renderHeader[T any](columns []Column[T], opts Options) elem.Node {
+	cells := make([]elem.Node, 0, len(columns))
+	for _, col := range columns {
+		props := attrs.Merge(col.Attrs, attrs.Props{attrs.Scope: "col"})
+		if col.SortKey == "" {
+			cells = append(cells, elem.Th(props, elem.Text(col.Header)))
+			continue
+		}
+
+		// Clicking the active column flips its order; any other column
+		// starts ascending.
+		next := Ascending
+		if col.SortKey == opts.Sort {
+			if opts.Order == Descending {
+				props[attrs.AriaSort] = "descending"
+			} else {
+				props[attrs.AriaSort] = "ascending"
+				next = Descending
+			}
+		}
+		href := linkURL(opts.BaseURL, url.Values{
+			SortParam:  {col.SortKey},
+			OrderParam: {next},
+			PageParam:  nil,
+		})
+		cells = append(cells, elem.Th(props, elem.A(linkAttrs(href, opts), elem.Text(col.Header))))
+	}
+	return elem.Tr(nil, cells...)
+}
+
+func renderBody[T any](rows []T, columns []Column[T], opts Options) []elem.Node {
+	if len(rows) == 0 {
+		empty := opts.Empty
+		if empty == nil {
+			empty = elem.Text("No results")
+		}
+		return []elem.Node{
+			elem.Tr(nil, elem.Td(attrs.Props{attrs.ColSpan: strconv.Itoa(len(columns))}, empty)),
+		}
+	}
+
+	return elem.TransformEach(rows, func(row T) elem.Node {
+		cells := make([]elem.Node, 0, len(columns))
+		for _, col := range columns {
+			cells = append(cells, elem.Td(col.Attrs, col.Cell(row)))
+		}
+		return elem.Tr(nil, cells...)
+	})
+}
+
+// renderPagination renders previous/next links around a window of page
+// numbers, eliding the pages far from the current one.
+func renderPagination(pages int, opts Options) elem.Node {
+	pageLink := func(page int, label string, props attrs.Props) elem.Node {
+		params := url.Values{PageParam: {strconv.Itoa(page)}}
+		if opts.Sort != "" {
+			params[SortParam] = []string{opts.Sort}
+			params[OrderParam] = []string{orderOrDefault(opts.Order)}
+		}
+		props = attrs.Merge(props, linkAttrs(linkURL(opts.BaseURL, params), opts))
+		return elem.Li(nil, elem.A(props, elem.Text(label)))
+	}
+
+	items := []elem.Node{}
+	if opts.Page > 1 {
+		items = append(items, pageLink(opts.Page-1, "Previous", attrs.Props{attrs.Rel: "prev"}))
+	}
+	for _, page := range pageWindow(opts.Page, pages) {
+		switch {
+		case page == 0:
+			items = append(items, elem.Li(attrs.Props{attrs.AriaHidden: "true"}, elem.Text("…")))
+		case page == opts.Page:
+			items = append(items, elem.Li(nil, elem.Span(attrs.Props{attrs.AriaCurrent: "page"}, elem.Text(strconv.Itoa(page)))))
+		default:
+			items = append(items, pageLink(page, strconv.Itoa(page), nil))
+		}
+	}
+	if opts.Page < pages {
+		items = append(items, pageLink(opts.Page+1, "Next", attrs.Props{attrs.Rel: "next"}))
+	}
+	return elem.Nav(attrs.Props{attrs.AriaLabel: "Pagination"}, elem.Ul(nil, items...))
+}
+
+// pageWindow returns the page numbers to show: the first and last pages and
+// those next to the current one, with 0 marking an elided gap.
+func pageWindow(current, pages int) []int {
+	var window []int
+	for page := 1; page <= pages; page++ {
+		if page == 1 || page == pages || (page >= current-1 && page <= current+1) {
+			window = append(window, page)
+		} else if len(window) > 0 && window[len(window)-1] != 0 {
+			window = append(window, 0)
+		}
+	}
+	return window
+}
+
+func orderOrDefault(order string) string {
+	if order == Descending {
+		return Descending
+	}
+	return Ascending
+}
+
+// linkURL returns base with params set in its query string. A nil value
+// removes the parameter.
+func linkURL(base string, params url.Values) string {
+	u, err := url.Parse(base)
+	if err != nil {
+		u = &url.URL{}
+	}
+	query := u.Query()
+	for key, values := range params {
+		if values == nil {
+			query.Del(key)
+		} else {
+			query[key] = values
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func linkAttrs(href string, opts Options) attrs.Props {
+	href = html.EscapeString(href)
+	props := attrs.Props{attrs.Href: href}
+	if opts.HXTarget != "" {
+		props[htmx.HXGet] = href
+		props[htmx.HXTarget] = opts.HXTarget
+		if opts.HXSwap != "" {
+			props[htmx.HXSwap] = opts.HXSwap
+		}
+	}
+	return props
+}
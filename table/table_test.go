@@ -0,0 +1,92 @@
+package table
+
+import (
+	"cmp"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+var columns = []Column[user]{
+	{
+		Header:  "Name",
+		Cell:    func(u user) elem.Node { return elem.Text(u.Name) },
+		SortKey: "name",
+		Compare: func(a, b user) int { return cmp.Compare(a.Name, b.Name) },
+	},
+	{
+		Header: "Age",
+		Cell:   func(u user) elem.Node { return elem.Text(strconv.Itoa(u.Age)) },
+	},
+}
+
+var users = []user{{"Carol", 41}, {"Alice", 30}, {"Bob", 25}}
+
+func TestNew(t *testing.T) {
+	expected := `<div><table><thead><tr><th scope="col"><a href="/users?order=asc&amp;sort=name">Name</a></th><th scope="col">Age</th></tr></thead>` +
+		`<tbody><tr><td>Carol</td><td>41</td></tr><tr><td>Alice</td><td>30</td></tr><tr><td>Bob</td><td>25</td></tr></tbody></table></div>`
+	assert.Equal(t, expected, New(users, columns, Options{BaseURL: "/users"}).Render())
+}
+
+func TestNewSorted(t *testing.T) {
+	out := New(users, columns, Options{BaseURL: "/users", Sort: "name", Order: Descending}).Render()
+	assert.Contains(t, out, `<th aria-sort="descending" scope="col"><a href="/users?order=asc&amp;sort=name">Name</a></th>`)
+	assert.Contains(t, out, `<tbody><tr><td>Carol</td><td>41</td></tr><tr><td>Bob</td><td>25</td></tr><tr><td>Alice</td><td>30</td></tr></tbody>`)
+
+	out = New(users, columns, Options{BaseURL: "/users", Sort: "name", Order: Ascending}).Render()
+	assert.Contains(t, out, `<th aria-sort="ascending" scope="col"><a href="/users?order=desc&amp;sort=name">Name</a></th>`)
+	assert.Contains(t, out, `<tbody><tr><td>Alice</td>`)
+
+	// The caller's slice must not be reordered.
+	assert.Equal(t, "Carol", users[0].Name)
+}
+
+func TestNewEmpty(t *testing.T) {
+	out := New(nil, columns, Options{}).Render()
+	assert.Contains(t, out, `<tbody><tr><td colspan="2">No results</td></tr></tbody>`)
+
+	out = New(nil, columns, Options{Empty: elem.Em(nil, elem.Text("Nobody here"))}).Render()
+	assert.Contains(t, out, `<td colspan="2"><em>Nobody here</em></td>`)
+}
+
+func TestNewPaginated(t *testing.T) {
+	out := New(users, columns, Options{BaseURL: "/users?q=a", PerPage: 2, Page: 2, Sort: "name"}).Render()
+	assert.Contains(t, out, `<tbody><tr><td>Carol</td><td>41</td></tr></tbody>`)
+	assert.Contains(t, out, `<nav aria-label="Pagination"><ul>`+
+		`<li><a href="/users?order=asc&amp;page=1&amp;q=a&amp;sort=name" rel="prev">Previous</a></li>`+
+		`<li><a href="/users?order=asc&amp;page=1&amp;q=a&amp;sort=name">1</a></li>`+
+		`<li><span aria-current="page">2</span></li></ul></nav>`)
+}
+
+func TestNewPreSlicedPage(t *testing.T) {
+	out := New(users[:1], columns, Options{PerPage: 1, TotalRows: 3}).Render()
+	assert.Contains(t, out, `<tbody><tr><td>Carol</td><td>41</td></tr></tbody>`)
+	assert.Contains(t, out, `<a href="?page=2" rel="next">Next</a>`)
+}
+
+func TestNewHTMX(t *testing.T) {
+	out := New(users, columns, Options{BaseURL: "/users", HXTarget: "#users", HXSwap: "outerHTML"}).Render()
+	assert.Contains(t, out, `<a href="/users?order=asc&amp;sort=name" hx-get="/users?order=asc&amp;sort=name" hx-swap="outerHTML" hx-target="#users">Name</a>`)
+}
+
+func TestFromQuery(t *testing.T) {
+	opts := FromQuery(url.Values{"sort": {"name"}, "order": {"desc"}, "page": {"3"}}, Options{PerPage: 10})
+	assert.Equal(t, Options{Sort: "name", Order: Descending, Page: 3, PerPage: 10}, opts)
+
+	opts = FromQuery(url.Values{"page": {"-1"}}, Options{})
+	assert.Equal(t, Options{}, opts)
+}
+
+func TestPageWindow(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3}, pageWindow(1, 3))
+	assert.Equal(t, []int{1, 0, 4, 5, 6, 0, 10}, pageWindow(5, 10))
+	assert.Equal(t, []int{1, 2, 0, 10}, pageWindow(1, 10))
+}
@@ -0,0 +1,420 @@
+package elem
+
+import (
+	"hash/fnv"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chasefleming/elem-go/attrs"
+)
+
+// Walk traverses the tree rooted at node in document order, calling fn for
+// each node. Children of an element are visited only if fn returns true for
+// the element. Fragments are visited like any other element.
+func Walk(node Node, fn func(Node) bool) {
+	if !fn(node) {
+		return
+	}
+	if e, ok := node.(*Element); ok && e != nil {
+		for _, child := range e.Children {
+			Walk(child, fn)
+		}
+	}
+}
+
+// WalkPaths is like Walk, but also passes each node's path in the format of
+// Change.Path. Fragments are inlined into their parent and None nodes are
+// skipped, so fn is never called for either.
+func WalkPaths(node Node, fn func(n Node, path string) bool) {
+	walkPaths(node, nodeName(node), fn)
+}
+
+func walkPaths(node Node, path string, fn func(Node, string) bool) {
+	if !fn(node, path) {
+		return
+	}
+	if e, ok := node.(*Element); ok && e != nil {
+		for i, child := range flattenChildren(e.Children) {
+			walkPaths(child, childPath(path, child, i), fn)
+		}
+	}
+}
+
+// Clone returns a deep copy of the element: its attributes and children are
+// copied, so modifying the clone never affects the original.
+func (e *Element) Clone() *Element {
+	if e == nil {
+		return nil
+	}
+	clone := &Element{Tag: e.Tag}
+	if e.Attrs != nil {
+		clone.Attrs = make(attrs.Props, len(e.Attrs))
+		for k, v := range e.Attrs {
+			clone.Attrs[k] = v
+		}
+	}
+	if e.Children != nil {
+		clone.Children = make([]Node, len(e.Children))
+		for i, child := range e.Children {
+			clone.Children[i] = CloneNode(child)
+		}
+	}
+	return clone
+}
+
+// CloneNode returns a deep copy of node. Elements are copied with Clone;
+// the other node types in this package are immutable values and are
+// returned as is, as are node types defined elsewhere.
+func CloneNode(node Node) Node {
+	switch n := node.(type) {
+	case *Element:
+		return n.Clone()
+	case ScriptNode:
+		return ScriptNode{CloneNode(n.node)}
+	}
+	return node
+}
+
+// Equal reports whether two trees render the same document. Attribute order
+// doesn't matter, fragments are compared by their children as if they were
+// inlined into the parent, None nodes are ignored, and a boolean attribute
+// set to anything but "true" is treated as absent. Nodes other than elements
+// are equal when they have the same type and render the same output, so
+// comparing them calls Render, which runs the functions of Lazy and Async
+// nodes.
+func Equal(a, b Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	ea, aIsElement := a.(*Element)
+	eb, bIsElement := b.(*Element)
+	if aIsElement != bIsElement {
+		return false
+	}
+	if !aIsElement {
+		return reflect.TypeOf(a) == reflect.TypeOf(b) && a.Render() == b.Render()
+	}
+	if ea == nil || eb == nil {
+		return ea == eb
+	}
+	if ea.Tag != eb.Tag || len(attrDiff(ea.Attrs, eb.Attrs)) > 0 {
+		return false
+	}
+	ca, cb := flattenChildren(ea.Children), flattenChildren(eb.Children)
+	return slices.EqualFunc(ca, cb, Equal)
+}
+
+// ChangeKind identifies the kind of a Change.
+type ChangeKind int
+
+const (
+	// NodeAdded is a node present only in the updated tree.
+	NodeAdded ChangeKind = iota
+	// NodeRemoved is a node present only in the old tree.
+	NodeRemoved
+	// NodeReplaced is a node whose tag, type or content changed, so it has
+	// to be replaced as a whole.
+	NodeReplaced
+	// AttrAdded, AttrRemoved and AttrChanged are attribute changes on an
+	// element present in both trees.
+	AttrAdded
+	AttrRemoved
+	AttrChanged
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case NodeAdded:
+		return "node added"
+	case NodeRemoved:
+		return "node removed"
+	case NodeReplaced:
+		return "node replaced"
+	case AttrAdded:
+		return "attribute added"
+	case AttrRemoved:
+		return "attribute removed"
+	case AttrChanged:
+		return "attribute changed"
+	}
+	return "unknown change"
+}
+
+// Change is a single difference between two trees, as reported by Diff.
+type Change struct {
+	Kind ChangeKind
+	// Path locates the node, e.g. "html/body[1]/ul[0]/li[2]". Each segment
+	// is a tag (or #text, #raw, #comment, ...) and the node's index among its
+	// parent's children after fragments are inlined and None nodes dropped.
+	// For added nodes the index is the position in the updated tree,
+	// otherwise in the old one.
+	Path string
+	// Old and New are the nodes involved; Old is nil for NodeAdded and New
+	// is nil for NodeRemoved.
+	Old, New Node
+	// Attr, OldValue and NewValue describe attribute changes.
+	Attr               string
+	OldValue, NewValue string
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case AttrAdded:
+		return c.Path + ": " + c.Kind.String() + " " + c.Attr + "=" + strconv.Quote(c.NewValue)
+	case AttrRemoved:
+		return c.Path + ": " + c.Kind.String() + " " + c.Attr
+	case AttrChanged:
+		return c.Path + ": " + c.Kind.String() + " " + c.Attr + " " + strconv.Quote(c.OldValue) + " -> " + strconv.Quote(c.NewValue)
+	}
+	return c.Path + ": " + c.Kind.String()
+}
+
+// Diff returns the changes that turn the tree old into updated, using
+// the same notion of equality as Equal. Children are aligned by their
+// longest common subsequence, so an insertion in the middle of a list is
+// reported as a single added node rather than as changes to every node
+// after it. Like Equal, it renders nodes other than elements to compare
+// them, running the functions of Lazy and Async nodes.
+func Diff(old, updated Node) []Change {
+	d := differ{hashes: make(map[*Element]nodeHash)}
+	d.node(nodeName(old), old, updated)
+	return d.changes
+}
+
+// differ holds the changes found by Diff and the hashes of the subtrees
+// compared so far.
+type differ struct {
+	changes []Change
+	hashes  map[*Element]nodeHash
+}
+
+func (d *differ) node(path string, old, updated Node) {
+	eo, oldIsElement := old.(*Element)
+	en, newIsElement := updated.(*Element)
+	if !oldIsElement || !newIsElement || eo == nil || en == nil || eo.Tag != en.Tag {
+		if !Equal(old, updated) {
+			d.changes = append(d.changes, Change{Kind: NodeReplaced, Path: path, Old: old, New: updated})
+		}
+		return
+	}
+
+	for _, c := range attrDiff(eo.Attrs, en.Attrs) {
+		c.Path = path
+		d.changes = append(d.changes, c)
+	}
+
+	co, cn := flattenChildren(eo.Children), flattenChildren(en.Children)
+	i, j := 0, 0
+	for _, match := range d.lcs(co, cn) {
+		d.run(path, co, cn, i, match[0], j, match[1])
+		i, j = match[0]+1, match[1]+1
+	}
+	d.run(path, co, cn, i, len(co), j, len(cn))
+}
+
+// run reports the changes between the unmatched children old[i:iEnd] and
+// updated[j:jEnd]. Pairs with the same tag are diffed recursively; the rest
+// are removals and additions.
+func (d *differ) run(path string, old, updated []Node, i, iEnd, j, jEnd int) {
+	for i < iEnd && j < jEnd && nodeName(old[i]) == nodeName(updated[j]) {
+		d.node(childPath(path, old[i], i), old[i], updated[j])
+		i++
+		j++
+	}
+	for ; i < iEnd; i++ {
+		d.changes = append(d.changes, Change{Kind: NodeRemoved, Path: childPath(path, old[i], i), Old: old[i]})
+	}
+	for ; j < jEnd; j++ {
+		d.changes = append(d.changes, Change{Kind: NodeAdded, Path: childPath(path, updated[j], j), New: updated[j]})
+	}
+}
+
+// lcs returns the index pairs of the longest common subsequence of equal
+// nodes in a and b. Nodes are compared by the hashes of their subtrees, so
+// each subtree is only traversed once however deep the tree is.
+func (d *differ) lcs(a, b []Node) [][2]int {
+	ha, hb := make([]nodeHash, len(a)), make([]nodeHash, len(b))
+	for i, n := range a {
+		ha[i] = d.hash(n)
+	}
+	for j, n := range b {
+		hb[j] = d.hash(n)
+	}
+
+	// lengths[i][j] is the LCS length of a[i:] and b[j:].
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if ha[i] == hb[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var pairs [][2]int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case ha[i] == hb[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// nodeHash is a hash of a subtree that is the same for trees that are
+// Equal. At 128 bits, different trees practically never share one.
+type nodeHash [16]byte
+
+// hash returns the hash of the subtree rooted at node, caching the hashes
+// of elements.
+func (d *differ) hash(node Node) nodeHash {
+	h := fnv.New128a()
+	e, isElement := node.(*Element)
+	switch {
+	case node == nil:
+		h.Write([]byte{0})
+	case !isElement:
+		t := reflect.TypeOf(node)
+		h.Write([]byte{1})
+		h.Write([]byte(t.PkgPath() + "." + t.String()))
+		h.Write([]byte{0})
+		h.Write([]byte(node.Render()))
+	case e == nil:
+		h.Write([]byte{2})
+	default:
+		if sum, ok := d.hashes[e]; ok {
+			return sum
+		}
+		h.Write([]byte{3})
+		h.Write([]byte(e.Tag))
+		keys := make([]string, 0, len(e.Attrs))
+		for k := range e.Attrs {
+			if _, ok := effectiveAttr(e.Attrs, k); ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			h.Write([]byte{0})
+			h.Write([]byte(k))
+			h.Write([]byte{0})
+			h.Write([]byte(e.Attrs[k]))
+		}
+		h.Write([]byte{1})
+		for _, child := range flattenChildren(e.Children) {
+			sum := d.hash(child)
+			h.Write(sum[:])
+		}
+	}
+
+	var sum nodeHash
+	h.Sum(sum[:0])
+	if isElement && e != nil {
+		d.hashes[e] = sum
+	}
+	return sum
+}
+
+// attrDiff returns the attribute changes between two attribute sets in
+// sorted attribute order, without paths.
+func attrDiff(old, updated attrs.Props) []Change {
+	keys := make([]string, 0, len(old)+len(updated))
+	for k := range old {
+		keys = append(keys, k)
+	}
+	for k := range updated {
+		if _, exists := old[k]; !exists {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var changes []Change
+	for _, k := range keys {
+		ov, inOld := effectiveAttr(old, k)
+		nv, inNew := effectiveAttr(updated, k)
+		switch {
+		case inOld && !inNew:
+			changes = append(changes, Change{Kind: AttrRemoved, Attr: k, OldValue: ov})
+		case !inOld && inNew:
+			changes = append(changes, Change{Kind: AttrAdded, Attr: k, NewValue: nv})
+		case inOld && inNew && ov != nv:
+			changes = append(changes, Change{Kind: AttrChanged, Attr: k, OldValue: ov, NewValue: nv})
+		}
+	}
+	return changes
+}
+
+// effectiveAttr returns the value of attribute k as it would be rendered,
+// reporting boolean attributes that aren't "true" as absent.
+func effectiveAttr(props attrs.Props, k string) (string, bool) {
+	v, exists := props[k]
+	if exists && IsBooleanAttr(k) && v != "true" {
+		return "", false
+	}
+	return v, exists
+}
+
+// flattenChildren inlines the children of fragments and drops None nodes.
+func flattenChildren(children []Node) []Node {
+	flat := make([]Node, 0, len(children))
+	for _, child := range children {
+		switch c := child.(type) {
+		case NoneNode:
+		case *Element:
+			if c != nil && c.Tag == "fragment" {
+				flat = append(flat, flattenChildren(c.Children)...)
+			} else {
+				flat = append(flat, c)
+			}
+		default:
+			flat = append(flat, child)
+		}
+	}
+	return flat
+}
+
+// nodeName returns the name used for node in change paths.
+func nodeName(node Node) string {
+	switch n := node.(type) {
+	case *Element:
+		if n != nil {
+			return n.Tag
+		}
+	case TextNode:
+		return "#text"
+	case RawNode:
+		return "#raw"
+	case CommentNode:
+		return "#comment"
+	case CdataNode:
+		return "#cdata"
+	case ScriptNode:
+		return "#script"
+	}
+	return "#node"
+}
+
+func childPath(parent string, child Node, index int) string {
+	var b strings.Builder
+	b.WriteString(parent)
+	b.WriteByte('/')
+	b.WriteString(nodeName(child))
+	b.WriteByte('[')
+	b.WriteString(strconv.Itoa(index))
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package elem
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	tree := Div(nil,
+		H1(nil, Text("Title")),
+		Ul(nil, Li(nil, Text("a")), Li(nil, Text("b"))),
+	)
+
+	var tags []string
+	Walk(tree, func(n Node) bool {
+		if e, ok := n.(*Element); ok {
+			tags = append(tags, e.Tag)
+			return e.Tag != "ul"
+		}
+		return true
+	})
+	assert.Equal(t, []string{"div", "h1", "ul"}, tags)
+}
+
+func TestWalkNilElement(t *testing.T) {
+	var missing *Element
+	tree := Div(nil, missing, P(nil))
+
+	var visited int
+	assert.NotPanics(t, func() {
+		Walk(tree, func(Node) bool {
+			visited++
+			return true
+		})
+	})
+	assert.Equal(t, 3, visited)
+}
+
+func TestWalkPaths(t *testing.T) {
+	tree := Div(nil,
+		H1(nil, Text("Title")),
+		Fragment(P(nil), None()),
+		Ul(nil, Li(nil, Text("a"))),
+	)
+
+	var paths []string
+	WalkPaths(tree, func(n Node, path string) bool {
+		paths = append(paths, path)
+		return true
+	})
+	assert.Equal(t, []string{"div", "div/h1[0]", "div/h1[0]/#text[0]", "div/p[1]", "div/ul[2]", "div/ul[2]/li[0]", "div/ul[2]/li[0]/#text[0]"}, paths)
+}
+
+func TestClone(t *testing.T) {
+	original := Div(attrs.Props{attrs.Class: "box"},
+		P(nil, Text("Hello")),
+		Script(nil, Raw("let a = 1")),
+	)
+	clone := original.Clone()
+	assert.Equal(t, original.Render(), clone.Render())
+
+	clone.Attrs[attrs.Class] = "changed"
+	clone.Children[0].(*Element).Children[0] = Text("Bye")
+	clone.Children = append(clone.Children, Hr(nil))
+
+	assert.Equal(t, "box", original.Attrs[attrs.Class])
+	assert.Equal(t, `<div class="box"><p>Hello</p><script>let a = 1</script></div>`, original.Render())
+	assert.Nil(t, (*Element)(nil).Clone())
+}
+
+func TestEqual(t *testing.T) {
+	a := Div(attrs.Props{attrs.ID: "a", attrs.Class: "x"}, P(nil, Text("Hi")))
+	b := Div(attrs.Props{attrs.Class: "x", attrs.ID: "a"}, P(attrs.Props{}, Text("Hi")))
+	assert.True(t, Equal(a, b))
+
+	assert.False(t, Equal(a, Div(attrs.Props{attrs.ID: "a"}, P(nil, Text("Hi")))))
+	assert.False(t, Equal(a, Div(attrs.Props{attrs.ID: "a", attrs.Class: "x"}, P(nil, Text("Ho")))))
+	assert.False(t, Equal(Text("<b>"), Raw("<b>")))
+
+	// Fragments and None are transparent, false boolean attributes absent.
+	assert.True(t, Equal(
+		Ul(nil, Li(nil), Fragment(Li(nil), None()), Li(nil)),
+		Ul(nil, Li(nil), Li(nil), Li(nil)),
+	))
+	assert.True(t, Equal(Input(attrs.Props{attrs.Disabled: "false"}), Input(nil)))
+}
+
+func TestDiff(t *testing.T) {
+	old := Ul(attrs.Props{attrs.Class: "list", attrs.ID: "items"},
+		Li(nil, Text("a")),
+		Li(nil, Text("b")),
+		Li(attrs.Props{attrs.Class: "last"}, Text("c")),
+	)
+	updated := Ul(attrs.Props{attrs.Class: "list wide", "data-x": "1"},
+		Li(nil, Text("a")),
+		Li(nil, Text("new")),
+		Li(nil, Text("b")),
+		Li(attrs.Props{attrs.Class: "final"}, Text("c")),
+	)
+
+	changes := Diff(old, updated)
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	assert.Equal(t, []string{
+		`ul: attribute changed class "list" -> "list wide"`,
+		`ul: attribute added data-x="1"`,
+		`ul: attribute removed id`,
+		`ul/li[1]: node added`,
+		`ul/li[2]: attribute changed class "last" -> "final"`,
+	}, lines)
+	assert.Equal(t, Text("new"), changes[3].New.(*Element).Children[0])
+}
+
+func TestDiffReplacedNodes(t *testing.T) {
+	changes := Diff(
+		Div(nil, P(nil, Text("a")), Span(nil)),
+		Div(nil, P(nil, Text("b")), Em(nil)),
+	)
+	assert.Equal(t, []Change{
+		{Kind: NodeReplaced, Path: "div/p[0]/#text[0]", Old: Text("a"), New: Text("b")},
+		{Kind: NodeRemoved, Path: "div/span[1]", Old: Span(nil)},
+		{Kind: NodeAdded, Path: "div/em[1]", New: Em(nil)},
+	}, changes)
+
+	assert.Empty(t, Diff(Div(nil, Text("same")), Div(nil, Text("same"))))
+	assert.Equal(t, NodeReplaced, Diff(Div(nil), Span(nil))[0].Kind)
+}
+
+func TestDiffNilElements(t *testing.T) {
+	var missing *Element
+	assert.Empty(t, Diff(Div(nil, missing), Div(nil, missing)))
+	assert.Equal(t, []Change{
+		{Kind: NodeRemoved, Path: "div/#node[0]", Old: missing},
+		{Kind: NodeAdded, Path: "div/p[0]", New: P(nil)},
+	}, Diff(Div(nil, missing), Div(nil, P(nil))))
+	assert.Equal(t, []Change{
+		{Kind: NodeReplaced, Path: "div", Old: Div(nil), New: missing},
+	}, Diff(Div(nil), missing))
+}
+
+func TestDiffDeepTree(t *testing.T) {
+	build := func(leaf string) Node {
+		var node Node = Text(leaf)
+		for i := 0; i < 500; i++ {
+			node = Div(attrs.Props{attrs.Class: "level"}, Span(nil, Text("sibling")), node)
+		}
+		return node
+	}
+
+	changes := Diff(build("old"), build("new"))
+	assert.Len(t, changes, 1)
+	assert.Equal(t, NodeReplaced, changes[0].Kind)
+	assert.Equal(t, Text("new"), changes[0].New)
+	assert.Empty(t, Diff(build("same"), build("same")))
+}
@@ -0,0 +1,32 @@
+package elem
+
+import "strings"
+
+// UsedCSSGenerator is implemented by CSS generators that can leave out the
+// rules of classes that aren't used, such as styles.StyleManager. When
+// rendering with RenderOptions.TreeShakeCSS, its CSS is generated for the
+// classes of the rendered tree only.
+type UsedCSSGenerator interface {
+	GenerateUsedCSS(used func(className string) bool, minify bool) string
+}
+
+// classSet collects the classes of the elements being rendered.
+type classSet struct {
+	names map[string]struct{}
+}
+
+func newClassSet() *classSet {
+	return &classSet{names: make(map[string]struct{})}
+}
+
+// add adds the classes of a class attribute.
+func (s *classSet) add(class string) {
+	for _, name := range strings.Fields(class) {
+		s.names[name] = struct{}{}
+	}
+}
+
+func (s *classSet) has(name string) bool {
+	_, ok := s.names[name]
+	return ok
+}
@@ -0,0 +1,50 @@
+package elem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/chasefleming/elem-go/styles"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeShakeCSS(t *testing.T) {
+	sm := styles.NewStyleManager()
+	fade := sm.AddAnimation(styles.Keyframes{"from": {styles.Opacity: "0"}, "to": {styles.Opacity: "1"}})
+	title := sm.AddStyle(styles.Props{styles.AnimationName: fade})
+	nav := sm.AddStyle(styles.Props{styles.Display: "flex"})
+	lazy := sm.AddStyle(styles.Props{styles.Color: "blue"})
+	unused := sm.AddStyle(styles.Props{styles.Color: "red"})
+	frozenNav := Freeze(Nav(attrs.Props{attrs.Class: nav}))
+
+	page := Html(nil,
+		Head(nil),
+		Body(nil,
+			frozenNav,
+			H1(attrs.Props{attrs.Class: attrs.ClassNames("title", title)}, Text("Hello")),
+			Lazy(func() Node { return P(attrs.Props{attrs.Class: lazy}) }),
+		),
+	)
+
+	html := page.RenderWithOptions(RenderOptions{StyleManager: sm, TreeShakeCSS: true, Minify: true})
+
+	assert.Contains(t, html, "<style>."+title+"{animation-name:"+fade+"}."+nav+"{display:flex}."+lazy+"{color:blue}@keyframes "+fade+"{from{opacity:0}to{opacity:1}}</style>")
+	assert.NotContains(t, html, unused)
+	assert.Contains(t, page.RenderWithOptions(RenderOptions{StyleManager: sm}), unused, "All styles should be included by default")
+}
+
+func TestTreeShakeCSSAsync(t *testing.T) {
+	sm := styles.NewStyleManager()
+	card := sm.AddStyle(styles.Props{styles.Padding: "1rem"})
+	unused := sm.AddStyle(styles.Props{styles.Margin: "0"})
+	page := Html(nil, Body(nil, Async(func(ctx context.Context) (Node, error) {
+		return Div(attrs.Props{attrs.Class: card}), nil
+	})))
+
+	html, err := RenderAsync(context.Background(), page, AsyncOptions{RenderOptions: RenderOptions{StyleManager: sm, TreeShakeCSS: true}})
+
+	assert.NoError(t, err)
+	assert.Contains(t, html, "."+card+" { padding: 1rem; }")
+	assert.NotContains(t, html, unused)
+}
@@ -43,6 +43,38 @@ func TransformEach[T any](items []T, fn func(T) Node) []Node {
 	return nodes
 }
 
+// MapEach maps a slice of items to nodes with fn and returns them as a
+// fragment, so the result can be used as a single child. (Map is the <map>
+// element.)
+func MapEach[T any](items []T, fn func(T) Node) *Element {
+	return Fragment(TransformEach(items, fn)...)
+}
+
+// MapIndexed is like MapEach, but also passes the index of each item to fn.
+func MapIndexed[T any](items []T, fn func(int, T) Node) *Element {
+	nodes := make([]Node, len(items))
+	for i, item := range items {
+		nodes[i] = fn(i, item)
+	}
+	return Fragment(nodes...)
+}
+
+// Join returns a fragment of nodes with separator between each of them,
+// such as elem.Text(", ") or elem.Hr(nil).
+func Join(separator Node, nodes ...Node) *Element {
+	if len(nodes) == 0 {
+		return Fragment()
+	}
+	joined := make([]Node, 0, 2*len(nodes)-1)
+	for i, node := range nodes {
+		if i > 0 {
+			joined = append(joined, separator)
+		}
+		joined = append(joined, node)
+	}
+	return Fragment(joined...)
+}
+
 // EscapeNodeContents escapes HTML5 special characters in a string to ensure safe rendering as a text node
 func EscapeNodeContents(s string) string {
 	return nodeContentReplacer.Replace(s)
@@ -1,8 +1,11 @@
 package elem
 
 import (
+	"strconv"
 	"testing"
 
+	"github.com/chasefleming/elem-go/attrs"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +45,29 @@ func TestTransformEach(t *testing.T) {
 	}
 }
 
+func TestMapEach(t *testing.T) {
+	items := []string{"a", "b"}
+
+	list := Ul(nil, MapEach(items, func(item string) Node {
+		return Li(nil, Text(item))
+	}))
+	assert.Equal(t, "<ul><li>a</li><li>b</li></ul>", list.Render())
+
+	indexed := Ol(nil, MapIndexed(items, func(i int, item string) Node {
+		return Li(attrs.Props{attrs.Value: strconv.Itoa(i + 1)}, Text(item))
+	}))
+	assert.Equal(t, `<ol><li value="1">a</li><li value="2">b</li></ol>`, indexed.Render())
+
+	assert.Equal(t, "", MapEach(nil, func(item string) Node { return Text(item) }).Render())
+}
+
+func TestJoin(t *testing.T) {
+	tags := Join(Text(", "), A(nil, Text("go")), A(nil, Text("html")), A(nil, Text("css")))
+	assert.Equal(t, "<p><a>go</a>, <a>html</a>, <a>css</a></p>", P(nil, tags).Render())
+	assert.Equal(t, "<a>go</a>", Join(Hr(nil), A(nil, Text("go"))).Render())
+	assert.Equal(t, "", Join(Hr(nil)).Render())
+}
+
 func TestTransformEachEmpty(t *testing.T) {
 	toLi := func(item string) Node { return Li(nil, Text(item)) }
 
@@ -0,0 +1,219 @@
+package elem
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Problem is a violation of the HTML content model found by Validate.
+type Problem struct {
+	// Path locates the element in the tree, in the format of Change.Path.
+	Path    string
+	Message string
+	Element *Element
+}
+
+// String formats the problem as "path: message".
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// Validate checks the tree rooted at node against the HTML content model and
+// returns the problems found in document order, or nil if there are none.
+// It reports the mistakes that make browsers silently restructure the DOM
+// or ignore markup:
+//
+//   - children of void elements
+//   - flow content, such as a Div, inside elements that only accept phrasing
+//     content, such as P, Span or the headings
+//   - interactive content nested in A or Button, and nested forms
+//   - elements outside of their required parent, such as Li outside of a
+//     list, Td outside of Tr or Tr directly in Table, where the browser
+//     inserts an implied tbody
+//   - text and elements inside lists, tables and selects that don't accept
+//     them
+//   - known HTML attributes used on elements that don't support them
+//
+// Custom elements, unknown tags and unknown attributes (such as hx-* or
+// data-*) are not checked. The node itself is not checked against a parent,
+// so partials like a lone Tr for an htmx swap validate cleanly.
+func Validate(node Node) []Problem {
+	v := &validator{}
+	v.validate(node, nodeName(node), validationContext{})
+	return v.problems
+}
+
+// ValidateHook returns a render hook that runs Validate on the tree being
+// rendered and passes any problems to report, such as a function logging
+// them. It panics if report is nil. Validation walks the whole tree on every
+// render, so use it in development only; when no hook is set, rendering
+// does no extra work.
+func ValidateHook(report func([]Problem)) RenderHook {
+	if report == nil {
+		panic("elem: ValidateHook requires a report function")
+	}
+	return RenderHookFunc(func(root Node) {
+		if problems := Validate(root); len(problems) > 0 {
+			report(problems)
+		}
+	})
+}
+
+// validationContext carries what the content model of an element depends on
+// beyond its parent.
+type validationContext struct {
+	interactive string // tag of the closest a or button ancestor
+	inForm      bool
+}
+
+type validator struct {
+	problems []Problem
+}
+
+func (v *validator) report(path string, e *Element, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...), Element: e})
+}
+
+func (v *validator) validate(node Node, path string, ctx validationContext) {
+	e, ok := node.(*Element)
+	if !ok || e == nil {
+		return
+	}
+	if e.Tag == "fragment" {
+		for i, child := range flattenChildren(e.Children) {
+			v.validate(child, childPath(path, child, i), ctx)
+		}
+		return
+	}
+	// Foreign content follows the SVG and MathML content models instead.
+	if e.Tag == "svg" || e.Tag == "math" || isCustomElement(e.Tag) {
+		return
+	}
+
+	v.validateAttrs(e, path)
+
+	children := flattenChildren(e.Children)
+	if IsVoidElement(e.Tag) {
+		if len(children) > 0 {
+			v.report(path, e, "void element <%s> cannot have children", e.Tag)
+		}
+		return
+	}
+
+	switch e.Tag {
+	case "form":
+		ctx.inForm = true
+	case "a", "button":
+		ctx.interactive = e.Tag
+	}
+
+	for i, child := range children {
+		childPath := childPath(path, child, i)
+		if text, ok := child.(TextNode); ok {
+			if _, restricted := allowedChildren[e.Tag]; restricted && strings.TrimSpace(string(text)) != "" {
+				v.report(childPath, e, "text cannot appear directly in <%s>", e.Tag)
+			}
+			continue
+		}
+		c, ok := child.(*Element)
+		if !ok {
+			continue
+		}
+		v.validateChild(e, c, childPath, ctx)
+		v.validate(c, childPath, ctx)
+	}
+}
+
+// validateChild reports at most one problem with c as a child of parent.
+func (v *validator) validateChild(parent, c *Element, path string, ctx validationContext) {
+	if isCustomElement(c.Tag) || c.Tag == "script" || c.Tag == "template" {
+		return
+	}
+	if allowed, restricted := allowedChildren[parent.Tag]; restricted {
+		if _, ok := allowed[c.Tag]; !ok {
+			v.report(path, c, "<%s> is not allowed as a child of <%s>", c.Tag, parent.Tag)
+			return
+		}
+	}
+	if parents, ok := requiredParents[c.Tag]; ok {
+		if _, ok := parents[parent.Tag]; !ok {
+			if c.Tag == "tr" && parent.Tag == "table" {
+				v.report(path, c, "<tr> directly in <table> gets an implied <tbody> from the browser; wrap rows in TBody, THead or TFoot")
+			} else {
+				v.report(path, c, "<%s> must be a child of %s, not <%s>", c.Tag, tagList(parents), parent.Tag)
+			}
+			return
+		}
+	}
+	if _, phrasingOnly := phrasingParents[parent.Tag]; phrasingOnly {
+		if _, phrasing := phrasingContent[c.Tag]; !phrasing && isKnownElement(c.Tag) {
+			v.report(path, c, "<%s> cannot contain <%s>, which is not phrasing content", parent.Tag, c.Tag)
+			return
+		}
+	}
+	if ctx.interactive != "" && isInteractive(c) {
+		v.report(path, c, "interactive <%s> cannot be nested inside <%s>", c.Tag, ctx.interactive)
+		return
+	}
+	if ctx.inForm && c.Tag == "form" {
+		v.report(path, c, "<form> cannot be nested inside another <form>")
+	}
+}
+
+func (v *validator) validateAttrs(e *Element, path string) {
+	if !isKnownElement(e.Tag) {
+		return
+	}
+	var invalid []string
+	for name := range e.Attrs {
+		elements, known := attrElements[name]
+		if !known {
+			continue
+		}
+		if _, present := effectiveAttr(e.Attrs, name); !present {
+			continue
+		}
+		if _, ok := elements[e.Tag]; !ok {
+			invalid = append(invalid, name)
+		}
+	}
+	slices.Sort(invalid)
+	for _, name := range invalid {
+		v.report(path, e, "attribute %s is not allowed on <%s>", name, e.Tag)
+	}
+}
+
+func isInteractive(e *Element) bool {
+	switch e.Tag {
+	case "a":
+		_, ok := e.Attrs["href"]
+		return ok
+	case "input":
+		return !strings.EqualFold(e.Attrs["type"], "hidden")
+	case "button", "select", "textarea", "details", "embed", "iframe", "label":
+		return true
+	case "audio", "video":
+		_, ok := effectiveAttr(e.Attrs, "controls")
+		return ok
+	}
+	return false
+}
+
+func isCustomElement(tag string) bool {
+	return strings.Contains(tag, "-")
+}
+
+func isKnownElement(tag string) bool {
+	_, ok := knownElements[tag]
+	return ok
+}
+
+func tagList(tags map[string]struct{}) string {
+	names := make([]string, 0, len(tags))
+	for tag := range tags {
+		names = append(names, "<"+tag+">")
+	}
+	slices.Sort(names)
+	return strings.Join(names, " or ")
+}
@@ -0,0 +1,86 @@
+package elem
+
+import (
+	"testing"
+
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/stretchr/testify/assert"
+)
+
+func problemStrings(problems []Problem) []string {
+	var out []string
+	for _, p := range problems {
+		out = append(out, p.String())
+	}
+	return out
+}
+
+func TestValidateValidTree(t *testing.T) {
+	page := Html(nil,
+		Head(nil, Title(nil, Text("Page")), Meta(attrs.Props{attrs.Charset: "utf-8"})),
+		Body(nil,
+			P(nil, Text("Hello "), A(attrs.Props{attrs.Href: "/"}, Strong(nil, Text("home")))),
+			Ul(nil, Fragment(Li(nil, Div(nil, Text("a"))), Li(nil)), Text("\n")),
+			Table(nil,
+				THead(nil, Tr(nil, Th(attrs.Props{attrs.Scope: "col"}, Text("Name")))),
+				TBody(nil, Tr(nil, Td(attrs.Props{attrs.ColSpan: "2"}, Text("x")))),
+			),
+			Form(attrs.Props{attrs.Action: "/", attrs.Method: "post"},
+				Label(attrs.Props{attrs.For: "q"}, Text("Search")),
+				Input(attrs.Props{attrs.ID: "q", attrs.Name: "q", "hx-get": "/search", attrs.Disabled: "false"}),
+				Select(nil, Optgroup(attrs.Props{attrs.Label: "G"}, Option(nil, Text("One")))),
+				Button(attrs.Props{attrs.Type: "submit"}, Span(nil, Text("Go"))),
+			),
+			CustomElement("my-card", nil, Div(nil)),
+		),
+	)
+	assert.Empty(t, Validate(page))
+
+	// Partials are not checked against a parent they don't have.
+	assert.Empty(t, Validate(Tr(nil, Td(nil, Text("row")))))
+}
+
+func TestValidateReportsProblems(t *testing.T) {
+	tree := Div(nil,
+		P(nil, Div(nil, Text("block in paragraph"))),
+		Li(nil, Text("stray")),
+		Ul(nil, Text("text"), Div(nil)),
+		Table(nil, Tr(nil, Td(nil))),
+		&Element{Tag: "br", Children: []Node{Text("x")}},
+		A(attrs.Props{attrs.Href: "/"}, Button(nil, Text("nested"))),
+		Form(nil, Div(nil, Form(nil))),
+		Div(attrs.Props{attrs.Href: "/", attrs.Checked: "true", attrs.Disabled: "false"}),
+	)
+
+	assert.Equal(t, []string{
+		"div/p[0]/div[0]: <p> cannot contain <div>, which is not phrasing content",
+		"div/li[1]: <li> must be a child of <menu> or <ol> or <ul>, not <div>",
+		"div/ul[2]/#text[0]: text cannot appear directly in <ul>",
+		"div/ul[2]/div[1]: <div> is not allowed as a child of <ul>",
+		"div/table[3]/tr[0]: <tr> directly in <table> gets an implied <tbody> from the browser; wrap rows in TBody, THead or TFoot",
+		"div/br[4]: void element <br> cannot have children",
+		"div/a[5]/button[0]: interactive <button> cannot be nested inside <a>",
+		"div/form[6]/div[0]/form[0]: <form> cannot be nested inside another <form>",
+		"div/div[7]: attribute checked is not allowed on <div>",
+		"div/div[7]: attribute href is not allowed on <div>",
+	}, problemStrings(Validate(tree)))
+}
+
+func TestValidateHook(t *testing.T) {
+	var problems []Problem
+	var calls int
+	hook := Hooks(
+		ValidateHook(func(p []Problem) { problems = p }),
+		nil,
+		RenderHookFunc(func(Node) { calls++ }),
+	)
+
+	html := Ul(nil, Div(nil)).RenderWithOptions(RenderOptions{Hook: hook})
+	assert.Equal(t, "<ul><div></div></ul>", html)
+	assert.Equal(t, []string{"ul/div[0]: <div> is not allowed as a child of <ul>"}, problemStrings(problems))
+	assert.Equal(t, 1, calls)
+}
+
+func TestValidateHookRequiresReport(t *testing.T) {
+	assert.Panics(t, func() { ValidateHook(nil) })
+}